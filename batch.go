@@ -0,0 +1,113 @@
+package brows
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+var ErrBatchSize = errors.New("brows: batch size must be positive")
+
+// ScanChan 逐行读取 rs, 每 n 行(最后一批可能不足 n 行)作为一批发送到 ch, 返回时关闭 ch 和 rs.
+// T 为 struct 或 *struct, 映射规则同 Rows.Scan. 每一批是新的切片, 接收方可以直接持有.
+// ctx 结束时停止读取并返回 ctx 的错误
+//
+// example:
+//
+//	ch := make(chan []User, 4)
+//	go func() {
+//		errc <- ScanChan(ctx, b.QueryContext(ctx, `select id,name from user`), ch, 1000)
+//	}()
+//	for batch := range ch {
+//		// 处理 batch
+//	}
+func ScanChan[T any](ctx context.Context, rs *Rows, ch chan<- []T, n int) (err error) {
+	defer close(ch)
+
+	if rs.err != nil {
+		return rs.err
+	}
+	defer func() {
+		rs.rows.Close()
+		err = rs.finish(err)
+	}()
+	if n <= 0 {
+		return ErrBatchSize
+	}
+
+	es, err := newElemScanner(rs.rows, reflect.TypeOf((*T)(nil)).Elem(), rs.opts)
+	if err != nil {
+		return err
+	}
+
+	send := func(batch []T) error {
+		select {
+		case ch <- batch:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	batch := make([]T, 0, n)
+	for rs.rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		one, err := es.scan()
+		if err != nil {
+			return err
+		}
+		batch = append(batch, one.Interface().(T))
+		rs.scanned++
+
+		if len(batch) == n {
+			if err := send(batch); err != nil {
+				return err
+			}
+			batch = make([]T, 0, n)
+		}
+	}
+	if err := rs.rows.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		if err := send(batch); err != nil {
+			return err
+		}
+	}
+
+	return rs.rows.Close()
+}
+
+// ScanBatches 逐行读取 rs, 每 n 行(最后一批可能不足 n 行)调用一次 fn, 返回时关闭 rs.
+// 读取在单独的 goroutine 中进行, fn 处理第 N 批时同时读取第 N+1 批. fn 返回错误或 ctx 结束时停止读取并返回该错误
+//
+// example:
+//
+//	err := ScanBatches(ctx, b.QueryContext(ctx, `select id,name from user`), 1000, func(batch []User) error {
+//		return export(batch)
+//	})
+func ScanBatches[T any](ctx context.Context, rs *Rows, n int, fn func(batch []T) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ch := make(chan []T, 1)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- ScanChan(ctx, rs, ch, n)
+	}()
+
+	for batch := range ch {
+		if err := fn(batch); err != nil {
+			cancel()
+			for range ch {
+			}
+			<-errc
+			return err
+		}
+	}
+
+	return <-errc
+}
@@ -0,0 +1,97 @@
+package brows
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/beanscc/brows/browstest"
+)
+
+type batchUser struct {
+	ID int64 `db:"id"`
+}
+
+func batchUsers(n int) *browstest.Result {
+	r := browstest.NewResult("id")
+	for i := 1; i <= n; i++ {
+		r.AddRow(int64(i))
+	}
+	return r
+}
+
+func TestScanBatches(t *testing.T) {
+	db := browstest.NewDB(batchUsers(5), batchUsers(5), batchUsers(5))
+	defer db.Close()
+	b := New(db)
+	ctx := context.Background()
+
+	var sizes []int
+	var last int64
+	err := ScanBatches(ctx, b.Query(`select id from user`), 2, func(batch []batchUser) error {
+		sizes = append(sizes, len(batch))
+		last = batch[len(batch)-1].ID
+		return nil
+	})
+	if err != nil || len(sizes) != 3 || sizes[0] != 2 || sizes[2] != 1 || last != 5 {
+		t.Errorf("ScanBatches got sizes:%v, last:%v, err:%v", sizes, last, err)
+	}
+
+	errStop := errors.New("stop")
+	calls := 0
+	err = ScanBatches(ctx, b.Query(`select id from user`), 2, func(batch []*batchUser) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || calls != 1 {
+		t.Errorf("ScanBatches want errStop after 1 call, got calls:%v, err:%v", calls, err)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	err = ScanBatches(cctx, b.Query(`select id from user`), 1, func(batch []batchUser) error {
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ScanBatches want context.Canceled, got:%v", err)
+	}
+}
+
+func TestScanChan(t *testing.T) {
+	db := browstest.NewDB(batchUsers(3), browstest.NewErrResult(errors.New("down")))
+	defer db.Close()
+	b := New(db)
+	ctx := context.Background()
+
+	ch := make(chan []*batchUser)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- ScanChan(ctx, b.Query(`select id from user`), ch, 2)
+	}()
+	var ids []int64
+	for batch := range ch {
+		for _, v := range batch {
+			ids = append(ids, v.ID)
+		}
+	}
+	if err := <-errc; err != nil || len(ids) != 3 || ids[2] != 3 {
+		t.Errorf("ScanChan got ids:%v, err:%v", ids, err)
+	}
+
+	// 查询出错时关闭 ch
+	ch = make(chan []*batchUser)
+	if err := ScanChan(ctx, b.Query(`select id from user`), ch, 2); err == nil {
+		t.Errorf("ScanChan want query error")
+	}
+	if _, ok := <-ch; ok {
+		t.Errorf("ScanChan want ch closed")
+	}
+
+	db.Push(batchUsers(1), batchUsers(1))
+	if err := ScanChan(ctx, b.Query(`select id from user`), make(chan []batchUser), 0); !errors.Is(err, ErrBatchSize) {
+		t.Errorf("ScanChan want ErrBatchSize, got:%v", err)
+	}
+	if err := ScanChan(ctx, b.Query(`select id from user`), make(chan []int), 1); !errors.Is(err, ErrSliceElement) {
+		t.Errorf("ScanChan want ErrSliceElement, got:%v", err)
+	}
+}
@@ -0,0 +1,289 @@
+// Package browstest 提供基于内存的 database/sql 驱动和查询记录器, 用于在没有真实数据库的情况下测试 brows 及其使用方.
+//
+// example:
+//
+//	db := browstest.NewDB(
+//		browstest.NewResult("id", "name").AddRow(int64(1), "a").AddRow(int64(2), "b"),
+//	)
+//	defer db.Close()
+//
+//	var users []User
+//	err := brows.New(db).Query(`select id,name from user where age > ?`, 10).Scan(&users)
+//
+//	calls := db.Calls() // [{Method:query Query:select id,name from user where age > ? Args:[10]}]
+package browstest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoResult        = errors.New("browstest: no result scripted")
+	ErrUnexpectedQuery = errors.New("browstest: unexpected query")
+)
+
+// Method 调用的方法
+type Method string
+
+const (
+	MethodQuery    Method = "query"
+	MethodExec     Method = "exec"
+	MethodPrepare  Method = "prepare"
+	MethodBegin    Method = "begin"
+	MethodCommit   Method = "commit"
+	MethodRollback Method = "rollback"
+	MethodPing     Method = "ping"
+)
+
+// Call 一次调用记录
+type Call struct {
+	Method Method
+	Query  string
+	Args   []any
+}
+
+// Column 列定义
+type Column struct {
+	Name string
+	// DatabaseType 数据库类型名称，如 BIGINT, VARCHAR
+	DatabaseType string
+	// ScanType 为空时，按该列第一个非 nil 值的类型推断
+	ScanType reflect.Type
+	Nullable bool
+}
+
+// Result 脚本化的查询(或执行)结果, 每次查询或执行依次消费一个 Result
+type Result struct {
+	// Query 非空时，要求实际执行的 SQL 与之相同，否则返回 ErrUnexpectedQuery
+	Query   string
+	Columns []Column
+	Rows    [][]driver.Value
+	// Err 查询或执行时返回的错误
+	Err error
+	// RowsErr 遍历完所有行后, Rows.Err() 返回的错误
+	RowsErr error
+	// Delay 返回结果前的延迟, 期间 context 结束则返回 context 的错误
+	Delay time.Duration
+
+	// 执行结果
+	LastInsertID int64
+	RowsAffected int64
+}
+
+// NewResult 返回指定列名的查询结果
+func NewResult(columns ...string) *Result {
+	r := &Result{}
+	for _, v := range columns {
+		r.Columns = append(r.Columns, Column{Name: v})
+	}
+
+	return r
+}
+
+// NewExecResult 返回执行结果
+func NewExecResult(lastInsertID, rowsAffected int64) *Result {
+	return &Result{LastInsertID: lastInsertID, RowsAffected: rowsAffected}
+}
+
+// NewErrResult 返回查询或执行时的错误
+func NewErrResult(err error) *Result {
+	return &Result{Err: err}
+}
+
+// AddRow 追加一行记录
+func (r *Result) AddRow(values ...driver.Value) *Result {
+	r.Rows = append(r.Rows, values)
+	return r
+}
+
+// ExpectQuery 设置期望执行的 SQL
+func (r *Result) ExpectQuery(query string) *Result {
+	r.Query = query
+	return r
+}
+
+// DB 基于内存驱动的 *sql.DB, 按顺序返回脚本化的结果，并记录所有调用
+type DB struct {
+	*sql.DB
+
+	mu      sync.Mutex
+	results []*Result
+	calls   []Call
+	pingErr error
+	// commitErr 事务 Commit 返回的错误
+	commitErr error
+}
+
+// NewDB 返回按顺序返回 results 的 DB
+func NewDB(results ...*Result) *DB {
+	db := &DB{results: results}
+	db.DB = sql.OpenDB(&connector{db: db})
+	return db
+}
+
+// Push 追加结果
+func (db *DB) Push(results ...*Result) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.results = append(db.results, results...)
+}
+
+// Pending 返回还未被消费的结果数量
+func (db *DB) Pending() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return len(db.results)
+}
+
+// SetPingError 设置 Ping 返回的错误
+func (db *DB) SetPingError(err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.pingErr = err
+}
+
+// SetCommitError 设置事务 Commit 返回的错误
+func (db *DB) SetCommitError(err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.commitErr = err
+}
+
+// Calls 返回所有调用记录
+func (db *DB) Calls() []Call {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return append([]Call(nil), db.calls...)
+}
+
+// Reset 清空未消费的结果和调用记录
+func (db *DB) Reset() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.results, db.calls = nil, nil
+}
+
+func (db *DB) record(method Method, query string, args []driver.NamedValue) {
+	call := Call{Method: method, Query: query}
+	for _, v := range args {
+		call.Args = append(call.Args, v.Value)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.calls = append(db.calls, call)
+}
+
+// next 记录调用并消费下一个结果
+func (db *DB) next(ctx context.Context, method Method, query string, args []driver.NamedValue) (*Result, error) {
+	db.record(method, query, args)
+
+	db.mu.Lock()
+	if 0 == len(db.results) {
+		db.mu.Unlock()
+		return nil, ErrNoResult
+	}
+	r := db.results[0]
+	db.results = db.results[1:]
+	db.mu.Unlock()
+
+	if "" != r.Query && r.Query != query {
+		return nil, fmt.Errorf("%w: got %q, want %q", ErrUnexpectedQuery, query, r.Query)
+	}
+
+	if r.Delay > 0 {
+		timer := time.NewTimer(r.Delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	return r, nil
+}
+
+func (db *DB) ping() error {
+	db.record(MethodPing, "", nil)
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.pingErr
+}
+
+func (db *DB) commit() error {
+	db.record(MethodCommit, "", nil)
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.commitErr
+}
+
+// Queryer brows.Query 接口
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Recorder 记录经过的查询和参数, 实现了 brows.Query 接口
+//
+// example:
+//
+//	rec := browstest.NewRecorder(db)
+//	brows.New(rec).Query(`select id from user where age > ?`, 10).Scan(&users)
+//	rec.Calls() // [{Method:query Query:select id from user where age > ? Args:[10]}]
+type Recorder struct {
+	next Queryer
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// NewRecorder 返回记录 next 上查询的 Recorder
+func NewRecorder(next Queryer) *Recorder {
+	return &Recorder{next: next}
+}
+
+func (r *Recorder) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	r.mu.Lock()
+	r.calls = append(r.calls, Call{Method: MethodQuery, Query: query, Args: append([]any(nil), args...)})
+	r.mu.Unlock()
+
+	return r.next.QueryContext(ctx, query, args...)
+}
+
+// Calls 返回所有查询记录
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Call(nil), r.calls...)
+}
+
+// Reset 清空查询记录
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = nil
+}
@@ -0,0 +1,180 @@
+package browstest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDB_Query(t *testing.T) {
+	db := NewDB(
+		NewResult("id", "name").AddRow(int64(1), "a").AddRow(int64(2), nil),
+		NewErrResult(sql.ErrConnDone),
+	)
+	defer db.Close()
+
+	rows, err := db.Query(`select id,name from user where age > ?`, 10)
+	if err != nil {
+		t.Fatalf("Query err:%v", err)
+	}
+
+	type row struct {
+		id   int64
+		name sql.NullString
+	}
+	var got []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.name); err != nil {
+			t.Fatalf("Scan err:%v", err)
+		}
+		got = append(got, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("Rows err:%v", err)
+	}
+
+	want := []row{{id: 1, name: sql.NullString{String: "a", Valid: true}}, {id: 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Query got:%v, want:%v", got, want)
+	}
+
+	if _, err := db.Query(`select 1`); !errors.Is(err, sql.ErrConnDone) {
+		t.Errorf("Query want sql.ErrConnDone, got:%v", err)
+	}
+
+	if _, err := db.Query(`select 1`); !errors.Is(err, ErrNoResult) {
+		t.Errorf("Query want ErrNoResult, got:%v", err)
+	}
+
+	calls := db.Calls()
+	wantCall := Call{Method: MethodQuery, Query: `select id,name from user where age > ?`, Args: []any{int64(10)}}
+	if len(calls) != 3 || !reflect.DeepEqual(calls[0], wantCall) {
+		t.Errorf("Calls got:%v", calls)
+	}
+}
+
+func TestDB_ColumnTypes(t *testing.T) {
+	r := NewResult().AddRow(nil, "a")
+	r.Columns = []Column{
+		{Name: "id", DatabaseType: "BIGINT", Nullable: true},
+		{Name: "name", DatabaseType: "VARCHAR", ScanType: reflect.TypeOf(sql.NullString{})},
+	}
+	r.AddRow(int64(1), "b")
+	db := NewDB(r)
+	defer db.Close()
+
+	rows, err := db.Query(`select id,name from user`)
+	if err != nil {
+		t.Fatalf("Query err:%v", err)
+	}
+	defer rows.Close()
+
+	cts, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatalf("ColumnTypes err:%v", err)
+	}
+
+	if cts[0].DatabaseTypeName() != "BIGINT" || cts[0].ScanType() != reflect.TypeOf(int64(0)) {
+		t.Errorf("ColumnTypes got unexpected id type:%s %s", cts[0].DatabaseTypeName(), cts[0].ScanType())
+	}
+	if nullable, ok := cts[0].Nullable(); !nullable || !ok {
+		t.Errorf("ColumnTypes want id nullable")
+	}
+	if cts[1].ScanType() != reflect.TypeOf(sql.NullString{}) {
+		t.Errorf("ColumnTypes got unexpected name scan type:%s", cts[1].ScanType())
+	}
+}
+
+func TestDB_ExpectQuery(t *testing.T) {
+	db := NewDB(NewResult("id").ExpectQuery(`select id from user`))
+	defer db.Close()
+
+	if _, err := db.Query(`select id from users`); !errors.Is(err, ErrUnexpectedQuery) {
+		t.Errorf("Query want ErrUnexpectedQuery, got:%v", err)
+	}
+}
+
+func TestDB_RowsErr(t *testing.T) {
+	r := NewResult("id").AddRow(int64(1))
+	r.RowsErr = sql.ErrTxDone
+	db := NewDB(r)
+	defer db.Close()
+
+	rows, err := db.Query(`select id from user`)
+	if err != nil {
+		t.Fatalf("Query err:%v", err)
+	}
+	for rows.Next() {
+	}
+	if err := rows.Err(); !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("Rows.Err want sql.ErrTxDone, got:%v", err)
+	}
+}
+
+func TestDB_Delay(t *testing.T) {
+	db := NewDB(&Result{Delay: time.Second})
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := db.QueryContext(ctx, `select 1`); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("QueryContext want context.DeadlineExceeded, got:%v", err)
+	}
+}
+
+func TestDB_ExecAndTx(t *testing.T) {
+	db := NewDB(NewExecResult(10, 1))
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin err:%v", err)
+	}
+	res, err := tx.Exec(`insert into user (name) values (?)`, "a")
+	if err != nil {
+		t.Fatalf("Exec err:%v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit err:%v", err)
+	}
+
+	id, _ := res.LastInsertId()
+	affected, _ := res.RowsAffected()
+	if id != 10 || affected != 1 {
+		t.Errorf("Exec got id:%d, affected:%d", id, affected)
+	}
+
+	var methods []Method
+	for _, v := range db.Calls() {
+		methods = append(methods, v.Method)
+	}
+	if want := []Method{MethodBegin, MethodExec, MethodCommit}; !reflect.DeepEqual(methods, want) {
+		t.Errorf("Calls got:%v, want:%v", methods, want)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	db := NewDB(NewResult("id").AddRow(int64(1)))
+	defer db.Close()
+
+	rec := NewRecorder(db)
+	rows, err := rec.QueryContext(context.Background(), `select id from user where age > ?`, 10)
+	if err != nil {
+		t.Fatalf("QueryContext err:%v", err)
+	}
+	rows.Close()
+
+	want := []Call{{Method: MethodQuery, Query: `select id from user where age > ?`, Args: []any{10}}}
+	if got := rec.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Recorder got:%v, want:%v", got, want)
+	}
+
+	rec.Reset()
+	if got := rec.Calls(); len(got) != 0 {
+		t.Errorf("Recorder Reset got:%v", got)
+	}
+}
@@ -0,0 +1,211 @@
+package browstest
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+)
+
+var (
+	_ driver.Connector          = (*connector)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.Pinger             = (*conn)(nil)
+	_ driver.StmtQueryContext   = (*stmt)(nil)
+	_ driver.StmtExecContext    = (*stmt)(nil)
+
+	_ driver.RowsColumnTypeScanType         = (*rows)(nil)
+	_ driver.RowsColumnTypeDatabaseTypeName = (*rows)(nil)
+	_ driver.RowsColumnTypeNullable         = (*rows)(nil)
+)
+
+var errDriverOpen = errors.New("browstest: use NewDB instead of sql.Open")
+
+var _anyType = reflect.TypeOf((*any)(nil)).Elem()
+
+type drv struct{}
+
+func (drv) Open(name string) (driver.Conn, error) {
+	return nil, errDriverOpen
+}
+
+type connector struct {
+	db *DB
+}
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{db: c.db}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return drv{}
+}
+
+type conn struct {
+	db *DB
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	c.db.record(MethodPrepare, query, nil)
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.db.record(MethodBegin, "", nil)
+	return &tx{conn: c}, nil
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	return c.db.ping()
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	r, err := c.db.next(ctx, MethodQuery, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rows{result: r}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	r, err := c.db.next(ctx, MethodExec, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return result{r}, nil
+}
+
+type tx struct {
+	conn *conn
+}
+
+func (t *tx) Commit() error {
+	return t.conn.db.commit()
+}
+
+func (t *tx) Rollback() error {
+	t.conn.db.record(MethodRollback, "", nil)
+	return nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), named(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), named(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	out := make([]driver.NamedValue, 0, len(args))
+	for i, v := range args {
+		out = append(out, driver.NamedValue{Ordinal: i + 1, Value: v})
+	}
+
+	return out
+}
+
+type result struct {
+	r *Result
+}
+
+func (r result) LastInsertId() (int64, error) {
+	return r.r.LastInsertID, nil
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return r.r.RowsAffected, nil
+}
+
+type rows struct {
+	result *Result
+	pos    int
+}
+
+func (r *rows) Columns() []string {
+	out := make([]string, 0, len(r.result.Columns))
+	for _, v := range r.result.Columns {
+		out = append(out, v.Name)
+	}
+
+	return out
+}
+
+func (r *rows) Close() error {
+	r.pos = len(r.result.Rows)
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.result.Rows) {
+		if err := r.result.RowsErr; err != nil {
+			return err
+		}
+		return io.EOF
+	}
+
+	copy(dest, r.result.Rows[r.pos])
+	r.pos++
+	return nil
+}
+
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	if st := r.result.Columns[index].ScanType; st != nil {
+		return st
+	}
+
+	for _, row := range r.result.Rows {
+		if index < len(row) && row[index] != nil {
+			return reflect.TypeOf(row[index])
+		}
+	}
+
+	return _anyType
+}
+
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.result.Columns[index].DatabaseType
+}
+
+func (r *rows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	return r.result.Columns[index].Nullable, true
+}
@@ -0,0 +1,305 @@
+package brows
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrUnboundBuilder = errors.New("brows: builder not bound to Brows, use Brows.Select")
+
+// Cond 查询条件, 返回以 ? 为占位符的 SQL 片段和参数
+type Cond interface {
+	ToSQL() (string, []any)
+}
+
+// Eq 等于条件, 多个 key 以 AND 连接. 值为 nil 时生成 IS NULL, 值为切片时生成 IN
+//
+//	Eq{"age": 10, "status": []int{1, 2}} // age = ? AND status IN (?,?)
+type Eq map[string]any
+
+// Ne 不等于条件, 多个 key 以 AND 连接. 值为 nil 时生成 IS NOT NULL, 值为切片时生成 NOT IN
+type Ne map[string]any
+
+// Gt 大于条件, 多个 key 以 AND 连接
+type Gt map[string]any
+
+// Gte 大于等于条件, 多个 key 以 AND 连接
+type Gte map[string]any
+
+// Lt 小于条件, 多个 key 以 AND 连接
+type Lt map[string]any
+
+// Lte 小于等于条件, 多个 key 以 AND 连接
+type Lte map[string]any
+
+// Like LIKE 条件, 多个 key 以 AND 连接
+type Like map[string]any
+
+// And 以 AND 连接多个条件
+type And []Cond
+
+// Or 以 OR 连接多个条件
+type Or []Cond
+
+func (c Eq) ToSQL() (string, []any) {
+	return compare(c, "=", "IS NULL", "IN", "1=0")
+}
+
+func (c Ne) ToSQL() (string, []any) {
+	return compare(c, "<>", "IS NOT NULL", "NOT IN", "1=1")
+}
+
+func (c Gt) ToSQL() (string, []any) {
+	return compare(c, ">", "", "", "")
+}
+
+func (c Gte) ToSQL() (string, []any) {
+	return compare(c, ">=", "", "", "")
+}
+
+func (c Lt) ToSQL() (string, []any) {
+	return compare(c, "<", "", "", "")
+}
+
+func (c Lte) ToSQL() (string, []any) {
+	return compare(c, "<=", "", "", "")
+}
+
+func (c Like) ToSQL() (string, []any) {
+	return compare(c, "LIKE", "", "", "")
+}
+
+func (c And) ToSQL() (string, []any) {
+	return join(c, " AND ")
+}
+
+func (c Or) ToSQL() (string, []any) {
+	return join(c, " OR ")
+}
+
+// Expr 返回原始 SQL 条件, query 以 ? 为占位符
+//
+//	Expr("created_at > NOW() - INTERVAL ? DAY", 7)
+func Expr(query string, args ...any) Cond {
+	return expr{query: query, args: args}
+}
+
+type expr struct {
+	query string
+	args  []any
+}
+
+func (e expr) ToSQL() (string, []any) {
+	return e.query, e.args
+}
+
+// compare 生成 m 中各 key 的比较条件, 按 key 排序以保证 SQL 稳定.
+// null 为值是 nil 时的条件, in 为值是切片时的操作符, empty 为值是空切片时的条件; 为空表示不做特殊处理
+func compare[M ~map[string]any](m M, op, null, in, empty string) (string, []any) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var (
+		parts []string
+		args  []any
+	)
+	for _, k := range keys {
+		v := m[k]
+		if nil == v && "" != null {
+			parts = append(parts, k+" "+null)
+			continue
+		}
+
+		if rv := reflect.ValueOf(v); "" != in && isList(rv) {
+			if 0 == rv.Len() {
+				parts = append(parts, empty)
+				continue
+			}
+			for i := 0; i < rv.Len(); i++ {
+				args = append(args, rv.Index(i).Interface())
+			}
+			parts = append(parts, k+" "+in+" ("+strings.TrimSuffix(strings.Repeat("?,", rv.Len()), ",")+")")
+			continue
+		}
+
+		parts = append(parts, k+" "+op+" ?")
+		args = append(args, v)
+	}
+
+	return strings.Join(parts, " AND "), args
+}
+
+// isList 判断 rv 是否为用于 IN 的切片或数组, []byte 除外
+func isList(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		return reflect.Uint8 != rv.Type().Elem().Kind()
+	default:
+		return false
+	}
+}
+
+func join(conds []Cond, sep string) (string, []any) {
+	var (
+		parts []string
+		args  []any
+	)
+	for _, c := range conds {
+		query, cargs := c.ToSQL()
+		if "" == query {
+			continue
+		}
+		parts = append(parts, "("+query+")")
+		args = append(args, cargs...)
+	}
+
+	return strings.Join(parts, sep), args
+}
+
+// SelectBuilder SELECT 语句构建器
+type SelectBuilder struct {
+	brows   *Brows
+	columns []string
+	from    string
+	where   []Cond
+	orderBy []string
+	limit   int
+	offset  int
+}
+
+// Select 返回 SELECT 语句构建器, columns 为空时查询 *. 未绑定 Brows, 只能通过 ToSQL 生成 SQL
+//
+// example:
+//
+//	query, args := Select("id", "name").From("user").Where(Eq{"age": 10}).OrderBy("id DESC").Limit(10).ToSQL()
+//	// select id,name from user where age = ? order by id DESC limit 10
+func Select(columns ...string) *SelectBuilder {
+	return &SelectBuilder{columns: columns, limit: -1, offset: -1}
+}
+
+// Select 返回绑定 b 的 SELECT 语句构建器, 生成的 SQL 使用 b 的占位符风格, 可直接查询
+//
+// example:
+//
+//	var users []User
+//	err := b.Select(Columns(User{})...).From("user").Where(Eq{"age": 10}).Query().Scan(&users)
+func (b *Brows) Select(columns ...string) *SelectBuilder {
+	sb := Select(columns...)
+	sb.brows = b
+	return sb
+}
+
+// From 设置查询的表
+func (sb *SelectBuilder) From(table string) *SelectBuilder {
+	sb.from = table
+	return sb
+}
+
+// Where 追加查询条件, 多个条件以 AND 连接
+func (sb *SelectBuilder) Where(conds ...Cond) *SelectBuilder {
+	sb.where = append(sb.where, conds...)
+	return sb
+}
+
+// OrderBy 追加排序, 如 "id DESC"
+func (sb *SelectBuilder) OrderBy(exprs ...string) *SelectBuilder {
+	sb.orderBy = append(sb.orderBy, exprs...)
+	return sb
+}
+
+// Limit 设置返回的最大行数
+func (sb *SelectBuilder) Limit(n int) *SelectBuilder {
+	sb.limit = n
+	return sb
+}
+
+// Offset 设置跳过的行数. 未设置 Limit 时, ? 占位符风格(MySQL)生成 limit 18446744073709551615 offset n,
+// 因为 MySQL 不支持单独的 offset
+func (sb *SelectBuilder) Offset(n int) *SelectBuilder {
+	sb.offset = n
+	return sb
+}
+
+// _maxLimit MySQL 的最大行数(uint64 最大值), 用于只有 offset 的查询
+const _maxLimit = "18446744073709551615"
+
+// ToSQL 返回 SQL 和参数. 绑定 Brows 时使用其占位符风格, 否则使用 ?
+func (sb *SelectBuilder) ToSQL() (string, []any) {
+	var b strings.Builder
+	b.WriteString("select ")
+	if 0 == len(sb.columns) {
+		b.WriteString("*")
+	} else {
+		b.WriteString(strings.Join(sb.columns, ","))
+	}
+	b.WriteString(" from ")
+	b.WriteString(sb.from)
+
+	var (
+		where string
+		args  []any
+	)
+	if 1 == len(sb.where) {
+		where, args = sb.where[0].ToSQL()
+	} else {
+		where, args = And(sb.where).ToSQL()
+	}
+	if "" != where {
+		b.WriteString(" where ")
+		b.WriteString(where)
+	}
+
+	if len(sb.orderBy) > 0 {
+		b.WriteString(" order by ")
+		b.WriteString(strings.Join(sb.orderBy, ","))
+	}
+
+	if sb.limit >= 0 {
+		b.WriteString(" limit " + strconv.Itoa(sb.limit))
+	} else if sb.offset >= 0 && (nil == sb.brows || PlaceholderQuestion == sb.brows.opts.placeholder) {
+		// MySQL 的 offset 必须跟在 limit 后, 以最大行数表示不限制
+		b.WriteString(" limit " + _maxLimit)
+	}
+	if sb.offset >= 0 {
+		b.WriteString(" offset " + strconv.Itoa(sb.offset))
+	}
+
+	query := b.String()
+	if sb.brows != nil {
+		query = sb.brows.opts.placeholder.rebind(query)
+	}
+
+	return query, args
+}
+
+// Query 执行查询
+func (sb *SelectBuilder) Query() *Rows {
+	return sb.QueryContext(context.Background())
+}
+
+// QueryContext 执行查询
+func (sb *SelectBuilder) QueryContext(ctx context.Context) *Rows {
+	if sb.brows == nil {
+		return &Rows{err: ErrUnboundBuilder}
+	}
+
+	query, args := sb.ToSQL()
+	return sb.brows.QueryContext(ctx, query, args...)
+}
+
+// QueryRow 执行查询, 返回第一行记录
+func (sb *SelectBuilder) QueryRow() *Row {
+	return sb.QueryRowContext(context.Background())
+}
+
+// QueryRowContext 执行查询, 返回第一行记录
+func (sb *SelectBuilder) QueryRowContext(ctx context.Context) *Row {
+	return &Row{rows: sb.QueryContext(ctx)}
+}
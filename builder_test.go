@@ -0,0 +1,150 @@
+package brows
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/beanscc/brows/browstest"
+)
+
+func TestCond(t *testing.T) {
+	test := []struct {
+		name      string
+		cond      Cond
+		wantQuery string
+		wantArgs  []any
+	}{
+		{name: "eq", cond: Eq{"b": 2, "a": 1}, wantQuery: "a = ? AND b = ?", wantArgs: []any{1, 2}},
+		{name: "eq null", cond: Eq{"a": nil}, wantQuery: "a IS NULL"},
+		{name: "eq in", cond: Eq{"a": []int{1, 2}}, wantQuery: "a IN (?,?)", wantArgs: []any{1, 2}},
+		{name: "eq empty in", cond: Eq{"a": []int{}}, wantQuery: "1=0"},
+		{name: "eq bytes", cond: Eq{"a": []byte("x")}, wantQuery: "a = ?", wantArgs: []any{[]byte("x")}},
+		{name: "ne", cond: Ne{"a": 1, "b": nil, "c": []string{"x"}}, wantQuery: "a <> ? AND b IS NOT NULL AND c NOT IN (?)", wantArgs: []any{1, "x"}},
+		{name: "compare", cond: And{Gt{"a": 1}, Gte{"b": 2}, Lt{"c": 3}, Lte{"d": 4}},
+			wantQuery: "(a > ?) AND (b >= ?) AND (c < ?) AND (d <= ?)", wantArgs: []any{1, 2, 3, 4}},
+		{name: "like", cond: Like{"name": "a%"}, wantQuery: "name LIKE ?", wantArgs: []any{"a%"}},
+		{name: "or", cond: Or{Eq{"a": 1}, And{Eq{"b": 2}, Expr("c > NOW() - INTERVAL ? DAY", 7)}},
+			wantQuery: "(a = ?) OR ((b = ?) AND (c > NOW() - INTERVAL ? DAY))", wantArgs: []any{1, 2, 7}},
+		{name: "empty", cond: And{Eq{}, Or{}}, wantQuery: ""},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := tt.cond.ToSQL()
+			if query != tt.wantQuery || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ToSQL got:%q %v, want:%q %v", query, args, tt.wantQuery, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSelectBuilder_ToSQL(t *testing.T) {
+	test := []struct {
+		name      string
+		builder   *SelectBuilder
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			name:      "all",
+			builder:   Select().From("user"),
+			wantQuery: "select * from user",
+		},
+		{
+			name:      "where",
+			builder:   Select("id", "name").From("user").Where(Eq{"age": 10}).OrderBy("id DESC").Limit(10).Offset(20),
+			wantQuery: "select id,name from user where age = ? order by id DESC limit 10 offset 20",
+			wantArgs:  []any{10},
+		},
+		{
+			name:      "offset",
+			builder:   Select("id").From("user").Offset(20),
+			wantQuery: "select id from user limit 18446744073709551615 offset 20",
+		},
+		{
+			name:      "dollar offset",
+			builder:   New(nil, WithPlaceholder(PlaceholderDollar)).Select("id").From("user").Offset(20),
+			wantQuery: "select id from user offset 20",
+		},
+		{
+			name:      "multi where",
+			builder:   Select("id").From("user").Where(Eq{"age": 10}).Where(Or{Eq{"status": []int{1, 2}}, Like{"name": "a%"}}),
+			wantQuery: "select id from user where (age = ?) AND ((status IN (?,?)) OR (name LIKE ?))",
+			wantArgs:  []any{10, 1, 2, "a%"},
+		},
+		{
+			name:      "dollar",
+			builder:   New(nil, WithPlaceholder(PlaceholderDollar)).Select("id").From("user").Where(Eq{"a": 1, "b": "?"}, Expr("c = '?'")),
+			wantQuery: "select id from user where (a = $1 AND b = $2) AND (c = '?')",
+			wantArgs:  []any{1, "?"},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := tt.builder.ToSQL()
+			if query != tt.wantQuery || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ToSQL got:%q %v, want:%q %v", query, args, tt.wantQuery, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSelectBuilder_Query(t *testing.T) {
+	type User struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+
+	db := browstest.NewDB(
+		browstest.NewResult("id", "name").AddRow(int64(1), "a").AddRow(int64(2), "b"),
+		browstest.NewResult("id", "name").AddRow(int64(1), "a"),
+	)
+	defer db.Close()
+	b := New(db)
+
+	var users []User
+	if err := b.Select(Columns(User{})...).From("user").Where(Gt{"age": 10}).Query().Scan(&users); err != nil {
+		t.Fatalf("Query err:%v", err)
+	}
+	if len(users) != 2 || users[1].Name != "b" {
+		t.Errorf("Query got:%v", users)
+	}
+
+	var user User
+	if err := b.Select("id", "name").From("user").Where(Eq{"id": 1}).QueryRow().Scan(&user); err != nil || user.ID != 1 {
+		t.Errorf("QueryRow got:%v, err:%v", user, err)
+	}
+
+	calls := db.Calls()
+	want := []browstest.Call{
+		{Method: browstest.MethodQuery, Query: "select id,name from user where age > ?", Args: []any{int64(10)}},
+		{Method: browstest.MethodQuery, Query: "select id,name from user where id = ?", Args: []any{int64(1)}},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("Calls got:%v, want:%v", calls, want)
+	}
+
+	if err := Select().From("user").Query().Scan(&users); !errors.Is(err, ErrUnboundBuilder) {
+		t.Errorf("Query want ErrUnboundBuilder, got:%v", err)
+	}
+}
+
+func TestPlaceholder_rebind(t *testing.T) {
+	test := []struct {
+		p     Placeholder
+		query string
+		want  string
+	}{
+		{p: PlaceholderQuestion, query: "a = ? and b = ?", want: "a = ? and b = ?"},
+		{p: PlaceholderDollar, query: "a = ? and b = ?", want: "a = $1 and b = $2"},
+		{p: PlaceholderDollar, query: "a = '?' and `b?` = ? and c = \"?\"", want: "a = '?' and `b?` = $1 and c = \"?\""},
+	}
+
+	for _, tt := range test {
+		if got := tt.p.rebind(tt.query); got != tt.want {
+			t.Errorf("rebind(%q) got:%q, want:%q", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,406 @@
+package brows
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var ErrNoCache = errors.New("brows: cache not configured, use WithCache")
+
+// Cache QueryCached 使用的缓存, 需要并发安全. value 为 Scan 的结果, 可能被多个调用方共享, 不能修改
+type Cache interface {
+	// Get 返回 key 的值, 不存在或已过期时 ok 为 false
+	Get(key string) (value any, ok bool)
+	// Set 设置 key 的值, ttl 小于等于 0 时不过期; tags 用于 DeleteTags
+	Set(key string, value any, ttl time.Duration, tags []string)
+	// Delete 删除 keys
+	Delete(keys ...string)
+	// DeleteTags 删除带有 tags 中任一标签的 key
+	DeleteTags(tags ...string)
+}
+
+// WithCache 设置 QueryCached 使用的缓存
+//
+// example:
+//
+//	New(db, WithCache(NewLRUCache(1024)))
+func WithCache(c Cache) Option {
+	return func(o *options) {
+		o.cache = c
+		o.flight = &flightGroup{calls: make(map[string]*flightCall)}
+	}
+}
+
+// CachedQuery 使用缓存的查询, 由 Brows.QueryCached 创建
+type CachedQuery struct {
+	brows *Brows
+	ctx   context.Context
+	ttl   time.Duration
+	query string
+	args  []any
+	key   string
+	tags  []string
+}
+
+// QueryCached 返回使用缓存的查询, 结果在 Scan 时按 key(默认由 query, args 和 dest 的类型生成)缓存 ttl.
+// 并发的相同查询(key 和 dest 类型相同)只执行一次, 该查询不随单个调用方的 ctx 取消(截止时间也不继承, 可通过 WithDefaultTimeout 限制).
+// 需通过 WithCache 设置缓存
+//
+// example:
+//
+//	var cities []City
+//	err := b.QueryCached(ctx, time.Minute, `select id,name from city where country = ?`, "CN").Tags("city").Scan(&cities)
+//
+//	b.InvalidateTags("city") // 更新 city 表后
+func (b *Brows) QueryCached(ctx context.Context, ttl time.Duration, query string, args ...any) *CachedQuery {
+	return &CachedQuery{
+		brows: b,
+		ctx:   ctx,
+		ttl:   ttl,
+		query: query,
+		args:  args,
+	}
+}
+
+// Key 设置缓存的 key, 用于 Brows.Invalidate. 不同 dest 类型使用同一个 key 时相互覆盖
+func (q *CachedQuery) Key(key string) *CachedQuery {
+	q.key = key
+	return q
+}
+
+// Tags 设置缓存的标签, 用于 Brows.InvalidateTags
+func (q *CachedQuery) Tags(tags ...string) *CachedQuery {
+	q.tags = append(q.tags, tags...)
+	return q
+}
+
+// Scan 复制结果到 dest. dest 为切片指针时同 Rows.Scan, 为结构体指针时同 Row.Scan.
+// 缓存命中时 dest 为缓存结果的浅拷贝, 切片元素为指针时与缓存共享, 不能修改
+func (q *CachedQuery) Scan(dest any) error {
+	cache := q.brows.opts.cache
+	if nil == cache {
+		return ErrNoCache
+	}
+
+	rv := reflect.ValueOf(dest)
+	if reflect.Pointer != rv.Kind() || rv.IsNil() {
+		return ErrScanDestination
+	}
+	rt := rv.Elem().Type()
+	if reflect.Slice != rt.Kind() && reflect.Struct != rt.Kind() {
+		return ErrScanDestination
+	}
+
+	key := q.key
+	if "" == key {
+		key = CacheKey(rt, q.query, q.args...)
+	}
+
+	if v, ok := cache.Get(key); ok && reflect.TypeOf(v) == rt {
+		cacheAssign(rv.Elem(), v)
+		return nil
+	}
+
+	// 合并的查询由多个调用方共享, 不随某个调用方的 ctx 取消; 各调用方等待时仍响应自己的 ctx
+	ctx := context.WithoutCancel(q.ctx)
+	if nil != q.brows.opts.slowLog {
+		// 查询在 flightGroup 的 goroutine 中执行, 慢查询日志记录发起合并查询的调用方
+		ctx = withCaller(ctx, caller())
+	}
+	flight := q.brows.opts.flight
+	v, err := flight.do(q.ctx, rt.String()+"\x00"+key, func(gen uint64) (any, error) {
+		out := reflect.New(rt)
+		var err error
+		if reflect.Slice == rt.Kind() {
+			err = q.brows.QueryContext(ctx, q.query, q.args...).Scan(out.Interface())
+		} else {
+			err = q.brows.QueryRowContext(ctx, q.query, q.args...).Scan(out.Interface())
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// 查询期间执行了 Invalidate/InvalidateTags 时, 结果可能已过期, 不写入缓存
+		flight.set(gen, func() {
+			cache.Set(key, out.Elem().Interface(), q.ttl, q.tags)
+		})
+		return out.Elem().Interface(), nil
+	})
+	if err != nil {
+		return err
+	}
+	if reflect.TypeOf(v) != rt {
+		return fmt.Errorf("%w: cached %T, dest %s", ErrScanDestination, v, rt)
+	}
+
+	cacheAssign(rv.Elem(), v)
+	return nil
+}
+
+// cacheAssign 复制缓存的值 v 到 dest, 切片复制元素, 避免追加时修改缓存
+func cacheAssign(dest reflect.Value, v any) {
+	src := reflect.ValueOf(v)
+	if reflect.Slice == src.Kind() {
+		if src.IsNil() {
+			dest.Set(src)
+			return
+		}
+		out := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		reflect.Copy(out, src)
+		src = out
+	}
+	dest.Set(src)
+}
+
+// Invalidate 删除缓存的 keys, 见 CachedQuery.Key 和 CacheKey.
+// 正在执行的 QueryCached 查询的结果不再写入缓存, 之后的调用重新查询
+func (b *Brows) Invalidate(keys ...string) {
+	if nil != b.opts.cache {
+		b.opts.flight.invalidate()
+		b.opts.cache.Delete(keys...)
+	}
+}
+
+// InvalidateTags 删除带有 tags 中任一标签的缓存, 见 CachedQuery.Tags.
+// 正在执行的 QueryCached 查询的结果不再写入缓存, 之后的调用重新查询
+func (b *Brows) InvalidateTags(tags ...string) {
+	if nil != b.opts.cache {
+		b.opts.flight.invalidate()
+		b.opts.cache.DeleteTags(tags...)
+	}
+}
+
+// CacheKey 返回 QueryCached 的默认 key, rt 为 Scan 的 dest 指向的类型, 如 reflect.TypeOf([]User(nil)).
+// args 按驱动接收的值生成 key: 指针取其指向的值, driver.Valuer 取 Value() 的结果
+func CacheKey(rt reflect.Type, query string, args ...any) string {
+	h := sha256.New()
+	h.Write([]byte(query))
+	for _, v := range args {
+		h.Write([]byte("\x00"))
+		h.Write([]byte(cacheArg(v)))
+	}
+
+	return "brows:" + rt.String() + ":" + hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// cacheArg 返回参数 v 传给驱动的值的文本
+func cacheArg(v any) string {
+	if named, ok := v.(sql.NamedArg); ok {
+		return "@" + named.Name + ":" + cacheArg(named.Value)
+	}
+	if dv, err := driver.DefaultParameterConverter.ConvertValue(v); err == nil {
+		v = dv
+	} else {
+		// 驱动自定义转换的类型(如 uint64 的最高位为 1), 解引用指针后格式化
+		rv := reflect.ValueOf(v)
+		for reflect.Pointer == rv.Kind() && !rv.IsNil() {
+			rv = rv.Elem()
+		}
+		if rv.IsValid() {
+			v = rv.Interface()
+		}
+	}
+
+	if t, ok := v.(time.Time); ok {
+		// 忽略单调时钟和时区, 与驱动发送的时间一致
+		return "time.Time=" + t.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%T=%v", v, v)
+}
+
+// flightGroup 合并并发的相同调用
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+	// gen 失效的次数, 调用开始后 gen 变化时其结果不再写入缓存
+	gen uint64
+}
+
+type flightCall struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+// do 在新的 goroutine 中执行 fn 并返回结果, 同一 key 正在执行时等待其结果. gen 为 fn 开始时的 flightGroup.gen.
+// 等待时 ctx 结束返回 ctx 的错误, 但不会取消 fn, fn 的结果仍交给其他调用方
+func (g *flightGroup) do(ctx context.Context, key string, fn func(gen uint64) (any, error)) (any, error) {
+	g.mu.Lock()
+	c, ok := g.calls[key]
+	if !ok {
+		c = &flightCall{done: make(chan struct{})}
+		g.calls[key] = c
+		gen := g.gen
+		go func() {
+			defer func() {
+				// fn 不在调用方的 goroutine 中执行, panic 转为错误返回给调用方
+				if r := recover(); r != nil {
+					c.err = fmt.Errorf("brows: panic in cached query: %v", r)
+				}
+				g.mu.Lock()
+				// invalidate 后 key 可能已是新的调用
+				if g.calls[key] == c {
+					delete(g.calls, key)
+				}
+				g.mu.Unlock()
+				close(c.done)
+			}()
+
+			c.value, c.err = fn(gen)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.value, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// invalidate 使正在执行的调用的结果不再写入缓存, 之后的调用不再等待它们, 需在删除缓存前调用
+func (g *flightGroup) invalidate() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.gen++
+	g.calls = make(map[string]*flightCall)
+}
+
+// set gen 之后没有 invalidate 时执行 fn, 与 invalidate 互斥
+func (g *flightGroup) set(gen uint64, fn func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if gen == g.gen {
+		fn()
+	}
+}
+
+// LRUCache 内存 LRU 缓存, 实现了 Cache
+type LRUCache struct {
+	size int
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+	tags  map[string]map[string]struct{}
+}
+
+type lruEntry struct {
+	key     string
+	value   any
+	expires time.Time
+	tags    []string
+}
+
+// NewLRUCache 返回最多缓存 size 个 key 的 LRUCache
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+		tags:  make(map[string]map[string]struct{}),
+	}
+}
+
+func (c *LRUCache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*lruEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.remove(e)
+		return nil, false
+	}
+	c.ll.MoveToFront(e)
+
+	return entry.value, true
+}
+
+func (c *LRUCache) Set(key string, value any, ttl time.Duration, tags []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+
+	entry := &lruEntry{key: key, value: value, tags: tags}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	c.items[key] = c.ll.PushFront(entry)
+	for _, tag := range tags {
+		if nil == c.tags[tag] {
+			c.tags[tag] = make(map[string]struct{})
+		}
+		c.tags[tag][key] = struct{}{}
+	}
+
+	for c.size > 0 && c.ll.Len() > c.size {
+		c.remove(c.ll.Back())
+	}
+}
+
+func (c *LRUCache) Delete(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if e, ok := c.items[key]; ok {
+			c.remove(e)
+		}
+	}
+}
+
+func (c *LRUCache) DeleteTags(tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			if e, ok := c.items[key]; ok {
+				c.remove(e)
+			}
+		}
+		delete(c.tags, tag)
+	}
+}
+
+// Len 返回缓存的 key 数量, 包括已过期未删除的
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+// remove 删除 e, 需持有锁
+func (c *LRUCache) remove(e *list.Element) {
+	entry := e.Value.(*lruEntry)
+	c.ll.Remove(e)
+	delete(c.items, entry.key)
+	for _, tag := range entry.tags {
+		if keys := c.tags[tag]; nil != keys {
+			delete(keys, entry.key)
+			if 0 == len(keys) {
+				delete(c.tags, tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package brows
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/beanscc/brows/browstest"
+)
+
+func TestLRUCache(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("a", 1, 0, []string{"t1"})
+	c.Set("b", 2, 0, []string{"t1", "t2"})
+	c.Get("a")
+	c.Set("c", 3, 0, nil) // 淘汰 b
+	if _, ok := c.Get("b"); ok || c.Len() != 2 {
+		t.Errorf("LRUCache want b evicted, len:%v", c.Len())
+	}
+	if len(c.tags["t2"]) != 0 {
+		t.Errorf("LRUCache want tag t2 removed, got:%v", c.tags)
+	}
+
+	c.DeleteTags("t1")
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("LRUCache want a deleted by tag")
+	}
+	c.Delete("c")
+	if c.Len() != 0 {
+		t.Errorf("LRUCache want empty, len:%v", c.Len())
+	}
+
+	c.Set("d", 4, time.Millisecond, nil)
+	time.Sleep(2 * time.Millisecond)
+	if _, ok := c.Get("d"); ok || c.Len() != 0 {
+		t.Errorf("LRUCache want d expired")
+	}
+}
+
+func TestBrows_QueryCached(t *testing.T) {
+	type City struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+	cities := func() *browstest.Result {
+		return browstest.NewResult("id", "name").AddRow(int64(1), "a").AddRow(int64(2), "b")
+	}
+	db := browstest.NewDB(cities(), cities(), cities())
+	defer db.Close()
+	b := New(db, WithCache(NewLRUCache(16)))
+	ctx := context.Background()
+
+	query := `select id,name from city where country = ?`
+	for i := 0; i < 3; i++ {
+		var out []City
+		if err := b.QueryCached(ctx, time.Minute, query, "CN").Tags("city").Scan(&out); err != nil || len(out) != 2 || out[0].Name != "a" {
+			t.Fatalf("QueryCached got:%v, err:%v", out, err)
+		}
+		// 修改结果不影响缓存
+		out[0].Name = "x"
+	}
+	if n := countCalls(db, browstest.MethodQuery); n != 1 {
+		t.Errorf("query got:%v, want:1", n)
+	}
+
+	// 不同的类型分别缓存
+	var one City
+	if err := b.QueryCached(ctx, time.Minute, query, "CN").Tags("city").Scan(&one); err != nil || one.ID != 1 || one.Name != "a" {
+		t.Errorf("QueryCached struct got:%v, err:%v", one, err)
+	}
+	if n := countCalls(db, browstest.MethodQuery); n != 2 {
+		t.Errorf("query got:%v, want:2", n)
+	}
+
+	b.InvalidateTags("city")
+	var out []City
+	if err := b.QueryCached(ctx, time.Minute, query, "CN").Key("cities").Scan(&out); err != nil || out[0].Name != "a" {
+		t.Errorf("QueryCached after invalidate got:%v, err:%v", out, err)
+	}
+	b.Invalidate("cities")
+	if _, ok := b.opts.cache.Get("cities"); ok {
+		t.Errorf("Invalidate want cities deleted")
+	}
+	if n := countCalls(db, browstest.MethodQuery); n != 3 {
+		t.Errorf("query got:%v, want:3", n)
+	}
+
+	if err := New(db).QueryCached(ctx, time.Minute, query).Scan(&out); !errors.Is(err, ErrNoCache) {
+		t.Errorf("QueryCached want ErrNoCache, got:%v", err)
+	}
+	if err := b.QueryCached(ctx, time.Minute, query).Scan(out); !errors.Is(err, ErrScanDestination) {
+		t.Errorf("QueryCached want ErrScanDestination, got:%v", err)
+	}
+}
+
+func TestBrows_QueryCached_Singleflight(t *testing.T) {
+	r := browstest.NewResult("id").AddRow(int64(1))
+	r.Delay = 50 * time.Millisecond
+	db := browstest.NewDB(r)
+	defer db.Close()
+	b := New(db, WithCache(NewLRUCache(16)))
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var out []struct {
+				ID int64 `db:"id"`
+			}
+			errs[i] = b.QueryCached(context.Background(), time.Minute, `select id from t`).Scan(&out)
+			if nil == errs[i] && (len(out) != 1 || out[0].ID != 1) {
+				errs[i] = errors.New("unexpected result")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("goroutine %d err:%v", i, err)
+		}
+	}
+	if n := countCalls(db, browstest.MethodQuery); n != 1 {
+		t.Errorf("query got:%v, want:1", n)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	rt := reflect.TypeOf([]int(nil))
+	if CacheKey(rt, "q", 1) == CacheKey(rt, "q", "1") || CacheKey(rt, "q", 1) != CacheKey(rt, "q", 1) {
+		t.Errorf("CacheKey got unexpected result")
+	}
+
+	// 按驱动接收的值生成 key
+	id, now, big := 1, time.Now(), uint64(1<<63)
+	if CacheKey(rt, "q", &id) != CacheKey(rt, "q", int64(1)) ||
+		CacheKey(rt, "q", sql.NullInt64{Int64: 1, Valid: true}) != CacheKey(rt, "q", 1) ||
+		CacheKey(rt, "q", now) != CacheKey(rt, "q", now.Round(0).In(time.FixedZone("", 3600))) ||
+		CacheKey(rt, "q", big) != CacheKey(rt, "q", &big) {
+		t.Errorf("CacheKey got different keys for the same driver values")
+	}
+}
+
+func TestBrows_QueryCached_PointerArg(t *testing.T) {
+	db := browstest.NewDB(browstest.NewResult("id").AddRow(int64(1)), browstest.NewResult("id").AddRow(int64(2)))
+	defer db.Close()
+	b := New(db, WithCache(NewLRUCache(16)))
+
+	type ID struct {
+		ID int64 `db:"id"`
+	}
+	id := 1
+	var r1, r2 ID
+	if err := b.QueryCached(context.Background(), time.Minute, `select id from t where id = ?`, &id).Scan(&r1); err != nil || r1.ID != 1 {
+		t.Fatalf("QueryCached got:%v, err:%v", r1, err)
+	}
+	id = 2
+	if err := b.QueryCached(context.Background(), time.Minute, `select id from t where id = ?`, &id).Scan(&r2); err != nil || r2.ID != 2 {
+		t.Errorf("QueryCached got:%v, err:%v", r2, err)
+	}
+	if n := countCalls(db, browstest.MethodQuery); n != 2 {
+		t.Errorf("query got:%v, want:2", n)
+	}
+}
+
+func TestBrows_QueryCached_KeyTypes(t *testing.T) {
+	type A struct {
+		ID int64 `db:"id"`
+	}
+	type B struct {
+		ID int64 `db:"id"`
+	}
+	slow := func() *browstest.Result {
+		r := browstest.NewResult("id").AddRow(int64(1))
+		r.Delay = 30 * time.Millisecond
+		return r
+	}
+	db := browstest.NewDB(slow(), slow())
+	defer db.Close()
+	b := New(db, WithCache(NewLRUCache(16)))
+	ctx := context.Background()
+
+	var (
+		wg     sync.WaitGroup
+		as     []A
+		one    B
+		aErr   error
+		oneErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		aErr = b.QueryCached(ctx, time.Minute, `select id from t`).Key("k").Scan(&as)
+	}()
+	go func() {
+		defer wg.Done()
+		oneErr = b.QueryCached(ctx, time.Minute, `select id from t`).Key("k").Scan(&one)
+	}()
+	wg.Wait()
+
+	if aErr != nil || len(as) != 1 || oneErr != nil || one.ID != 1 {
+		t.Errorf("QueryCached got as:%v, err:%v, one:%v, err:%v", as, aErr, one, oneErr)
+	}
+	if n := countCalls(db, browstest.MethodQuery); n != 2 {
+		t.Errorf("query got:%v, want:2", n)
+	}
+}
+
+func TestBrows_QueryCached_Cancel(t *testing.T) {
+	r := browstest.NewResult("id").AddRow(int64(1))
+	r.Delay = 50 * time.Millisecond
+	db := browstest.NewDB(r)
+	defer db.Close()
+	b := New(db, WithCache(NewLRUCache(16)))
+
+	type ID struct {
+		ID int64 `db:"id"`
+	}
+	cctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		var out []ID
+		errc <- b.QueryCached(cctx, time.Minute, `select id from t`).Scan(&out)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	var out []ID
+	done := make(chan error, 1)
+	go func() {
+		done <- b.QueryCached(context.Background(), time.Minute, `select id from t`).Scan(&out)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled caller want context.Canceled, got:%v", err)
+	}
+	if err := <-done; err != nil || len(out) != 1 {
+		t.Errorf("waiter got:%v, err:%v", out, err)
+	}
+	if n := countCalls(db, browstest.MethodQuery); n != 1 {
+		t.Errorf("query got:%v, want:1", n)
+	}
+}
+
+func TestBrows_QueryCached_InvalidateInFlight(t *testing.T) {
+	r := browstest.NewResult("id").AddRow(int64(1))
+	r.Delay = 50 * time.Millisecond
+	db := browstest.NewDB(r, browstest.NewResult("id").AddRow(int64(2)))
+	defer db.Close()
+	b := New(db, WithCache(NewLRUCache(16)))
+
+	type ID struct {
+		ID int64 `db:"id"`
+	}
+	done := make(chan error, 1)
+	go func() {
+		var out []ID
+		done <- b.QueryCached(context.Background(), time.Minute, `select id from t`).Tags("t").Scan(&out)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	// 查询期间更新了数据
+	b.InvalidateTags("t")
+	if err := <-done; err != nil {
+		t.Fatalf("QueryCached err:%v", err)
+	}
+
+	var out []ID
+	if err := b.QueryCached(context.Background(), time.Minute, `select id from t`).Tags("t").Scan(&out); err != nil || len(out) != 1 || out[0].ID != 2 {
+		t.Errorf("QueryCached after invalidate got:%v, err:%v", out, err)
+	}
+	if n := countCalls(db, browstest.MethodQuery); n != 2 {
+		t.Errorf("query got:%v, want:2", n)
+	}
+}
@@ -0,0 +1,248 @@
+package brows
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Balance 从库的负载均衡方式
+type Balance uint8
+
+const (
+	// BalanceRoundRobin 轮询健康的从库
+	BalanceRoundRobin Balance = iota
+	// BalanceLeastLatency 选择延迟(查询和健康检查耗时的滑动平均)最低的健康从库
+	BalanceLeastLatency
+)
+
+// ClusterOption Cluster 的配置项
+type ClusterOption func(o *clusterOptions)
+
+type clusterOptions struct {
+	balance       Balance
+	checkInterval time.Duration
+	checkTimeout  time.Duration
+}
+
+// ClusterBalance 设置从库的负载均衡方式, 默认 BalanceRoundRobin
+func ClusterBalance(balance Balance) ClusterOption {
+	return func(o *clusterOptions) {
+		o.balance = balance
+	}
+}
+
+// ClusterHealthCheck 每隔 interval 通过 Ping 检查从库, 失败的从库不再接收查询, 直到检查恢复.
+// timeout 为单次 Ping 的超时时间, 为 0 时使用 interval. 默认不检查
+func ClusterHealthCheck(interval, timeout time.Duration) ClusterOption {
+	return func(o *clusterOptions) {
+		o.checkInterval = interval
+		o.checkTimeout = timeout
+	}
+}
+
+type primaryKey struct{}
+
+// WithPrimary 返回强制从主库读取的 ctx, 用于写入后立即读取等场景
+//
+// example:
+//
+//	ctx = WithPrimary(ctx)
+//	err := b.QueryRowContext(ctx, `select id,name from user where id = ?`, id).Scan(&user)
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// Cluster 主从集群, 实现了 Query. 查询路由到健康的从库, 写入、事务和 WithPrimary 的查询路由到主库, 没有健康的从库时查询主库
+//
+// example:
+//
+//	c := NewClusterWithOptions(primary, []*sql.DB{replica1, replica2}, ClusterBalance(BalanceLeastLatency), ClusterHealthCheck(time.Second, 0))
+//	defer c.Close()
+//	b := New(c)
+type Cluster struct {
+	primary  *sql.DB
+	replicas []*replica
+	opts     *clusterOptions
+
+	next uint64
+	stop chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+type replica struct {
+	db      *sql.DB
+	healthy atomic.Bool
+	// latency 耗时的滑动平均, 单位纳秒, 0 表示未统计
+	latency atomic.Int64
+}
+
+// observe 记录一次耗时
+func (r *replica) observe(d time.Duration) {
+	for {
+		old := r.latency.Load()
+		v := int64(d)
+		if old > 0 {
+			v = old + (int64(d)-old)/5
+		}
+		if r.latency.CompareAndSwap(old, v) {
+			return
+		}
+	}
+}
+
+// NewCluster 返回主库为 primary, 从库为 replicas 的集群. 开启健康检查时需调用 Close 停止检查
+func NewCluster(primary *sql.DB, replicas ...*sql.DB) *Cluster {
+	return NewClusterWithOptions(primary, replicas)
+}
+
+// NewClusterWithOptions 同 NewCluster, opts 为集群的配置项
+func NewClusterWithOptions(primary *sql.DB, replicas []*sql.DB, opts ...ClusterOption) *Cluster {
+	o := &clusterOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	c := &Cluster{
+		primary: primary,
+		opts:    o,
+		stop:    make(chan struct{}),
+	}
+	for _, db := range replicas {
+		r := &replica{db: db}
+		r.healthy.Store(true)
+		c.replicas = append(c.replicas, r)
+	}
+
+	if o.checkInterval > 0 && len(c.replicas) > 0 {
+		c.wg.Add(1)
+		go c.healthCheck()
+	}
+
+	return c
+}
+
+// Primary 返回主库
+func (c *Cluster) Primary() *sql.DB {
+	return c.primary
+}
+
+// QueryContext 在从库(WithPrimary 时在主库)查询
+func (c *Cluster) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	r := c.pick(ctx)
+	if nil == r {
+		return c.primary.QueryContext(ctx, query, args...)
+	}
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if nil == err {
+		r.observe(time.Since(start))
+	}
+
+	return rows, err
+}
+
+// ExecContext 在主库执行
+func (c *Cluster) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.primary.ExecContext(ctx, query, args...)
+}
+
+// BeginTx 在主库开启事务
+func (c *Cluster) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return c.primary.BeginTx(ctx, opts)
+}
+
+// CheckHealth 立即 Ping 所有从库并更新其健康状态
+func (c *Cluster) CheckHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, r := range c.replicas {
+		wg.Add(1)
+		go func(r *replica) {
+			defer wg.Done()
+
+			pctx := ctx
+			if timeout := c.checkTimeout(); timeout > 0 {
+				var cancel context.CancelFunc
+				pctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			err := r.db.PingContext(pctx)
+			if nil == err {
+				r.observe(time.Since(start))
+			}
+			r.healthy.Store(nil == err)
+		}(r)
+	}
+	wg.Wait()
+}
+
+// Close 停止健康检查, 不会关闭主库和从库
+func (c *Cluster) Close() error {
+	c.once.Do(func() {
+		close(c.stop)
+	})
+	c.wg.Wait()
+
+	return nil
+}
+
+// checkTimeout 返回单次 Ping 的超时时间, 0 表示不超时
+func (c *Cluster) checkTimeout() time.Duration {
+	if c.opts.checkTimeout > 0 {
+		return c.opts.checkTimeout
+	}
+
+	return c.opts.checkInterval
+}
+
+func (c *Cluster) healthCheck() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.opts.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.CheckHealth(context.Background())
+		}
+	}
+}
+
+// pick 返回查询的从库, 需要查询主库时返回 nil
+func (c *Cluster) pick(ctx context.Context) *replica {
+	if primary, _ := ctx.Value(primaryKey{}).(bool); primary {
+		return nil
+	}
+
+	var picked *replica
+	switch c.opts.balance {
+	case BalanceLeastLatency:
+		for _, r := range c.replicas {
+			if !r.healthy.Load() {
+				continue
+			}
+			if nil == picked || r.latency.Load() < picked.latency.Load() {
+				picked = r
+			}
+		}
+	default:
+		n := uint64(len(c.replicas))
+		start := atomic.AddUint64(&c.next, 1) - 1
+		for i := uint64(0); i < n; i++ {
+			if r := c.replicas[(start+i)%n]; r.healthy.Load() {
+				picked = r
+				break
+			}
+		}
+	}
+
+	return picked
+}
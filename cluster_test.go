@@ -0,0 +1,91 @@
+package brows
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/beanscc/brows/browstest"
+)
+
+func TestCluster(t *testing.T) {
+	id := func(v int64) *browstest.Result {
+		return browstest.NewResult("id").AddRow(v)
+	}
+	primary := browstest.NewDB(id(0), browstest.NewExecResult(1, 1), id(0))
+	r1 := browstest.NewDB(id(1), id(1))
+	r2 := browstest.NewDB(id(2), id(2))
+	defer primary.Close()
+	defer r1.Close()
+	defer r2.Close()
+
+	c := NewCluster(primary.DB, r1.DB, r2.DB)
+	defer c.Close()
+	b := New(c)
+	ctx := context.Background()
+
+	var out struct {
+		ID int64 `db:"id"`
+	}
+	var got []int64
+	for i := 0; i < 3; i++ {
+		if err := b.QueryRowContext(ctx, `select id from user`).Scan(&out); err != nil {
+			t.Fatalf("QueryRow err:%v", err)
+		}
+		got = append(got, out.ID)
+	}
+	if err := b.QueryRowContext(WithPrimary(ctx), `select id from user`).Scan(&out); err != nil {
+		t.Fatalf("QueryRow WithPrimary err:%v", err)
+	}
+	got = append(got, out.ID)
+	if want := []int64{1, 2, 1, 0}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] || got[3] != want[3] {
+		t.Errorf("Cluster routing got:%v, want:%v", got, want)
+	}
+
+	if _, err := c.ExecContext(ctx, `update user set name = ?`, "a"); err != nil {
+		t.Errorf("ExecContext err:%v", err)
+	}
+
+	// r1 不健康时只查询 r2, 全部不健康时查询主库
+	r1.SetPingError(errors.New("down"))
+	c.CheckHealth(ctx)
+	if err := b.QueryRowContext(ctx, `select id from user`).Scan(&out); err != nil || out.ID != 2 {
+		t.Errorf("QueryRow with r1 down got:%v, err:%v", out.ID, err)
+	}
+	r2.SetPingError(errors.New("down"))
+	c.CheckHealth(ctx)
+	if err := b.QueryRowContext(ctx, `select id from user`).Scan(&out); err != nil || out.ID != 0 {
+		t.Errorf("QueryRow with all down got:%v, err:%v", out.ID, err)
+	}
+	if n := countCalls(primary, browstest.MethodExec); n != 1 {
+		t.Errorf("primary exec got:%v, want:1", n)
+	}
+}
+
+func TestCluster_LeastLatency(t *testing.T) {
+	r1 := browstest.NewDB()
+	r2 := browstest.NewDB()
+	defer r1.Close()
+	defer r2.Close()
+
+	c := NewClusterWithOptions(nil, []*sql.DB{r1.DB, r2.DB}, ClusterBalance(BalanceLeastLatency), ClusterHealthCheck(time.Millisecond, time.Second))
+	c.replicas[0].observe(10 * time.Millisecond)
+	c.replicas[1].observe(time.Millisecond)
+	if r := c.pick(context.Background()); r != c.replicas[1] {
+		t.Errorf("pick want r2")
+	}
+
+	r2.SetPingError(errors.New("down"))
+	deadline := time.Now().Add(time.Second)
+	for c.replicas[1].healthy.Load() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if r := c.pick(context.Background()); r != c.replicas[0] {
+		t.Errorf("pick after health check want r1")
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Close err:%v", err)
+	}
+}
@@ -0,0 +1,39 @@
+// Code generated by brows-gen. DO NOT EDIT.
+
+package example
+
+import "github.com/beanscc/brows"
+
+// ScanBrows 将 columns 对应的 Scan 目标写入 dest, 实现 brows.ColumnScanner
+func (v *User) ScanBrows(columns []string, dest []any) {
+	for i, column := range columns {
+		switch column {
+		case "id":
+			dest[i] = &v.ID
+		case "name":
+			dest[i] = &v.Name
+		case "age":
+			dest[i] = &v.Age
+		case "status":
+			dest[i] = &v.Status
+		case "note":
+			dest[i] = &v.Note
+		case "created_at":
+			dest[i] = &v.Audit.CreatedAt
+		case "deleted_at":
+			dest[i] = &v.Audit.DeletedAt
+		case "email":
+			if v.Profile == nil {
+				v.Profile = new(Profile)
+			}
+			dest[i] = &v.Profile.Email
+		case "phone":
+			if v.Profile == nil {
+				v.Profile = new(Profile)
+			}
+			dest[i] = &v.Profile.Phone
+		default:
+			dest[i] = brows.Discard
+		}
+	}
+}
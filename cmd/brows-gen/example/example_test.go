@@ -0,0 +1,86 @@
+package example
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/beanscc/brows"
+	"github.com/beanscc/brows/browstest"
+)
+
+// reflectUser 与 User 字段相同, 但没有 ScanBrows 方法, 通过反射映射字段
+type reflectUser User
+
+func userResult(n int) *browstest.Result {
+	r := browstest.NewResult("id", "name", "age", "status", "note", "created_at", "deleted_at", "email", "phone", "other")
+	at := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		var email driver.Value
+		if i%2 == 0 {
+			email = "user@example.com"
+		}
+		r.AddRow(int64(i), "name", int64(20), int64(1), "note", at, nil, email, "123456", "other")
+	}
+
+	return r
+}
+
+func TestUser_ScanBrows(t *testing.T) {
+	db := browstest.NewDB(userResult(3), userResult(3), userResult(3))
+	defer db.Close()
+	b := brows.New(db)
+
+	var generated []User
+	if err := b.Query(`select * from user`).Scan(&generated); err != nil {
+		t.Fatalf("Scan generated err:%v", err)
+	}
+
+	var reflected []reflectUser
+	if err := b.Query(`select * from user`).Scan(&reflected); err != nil {
+		t.Fatalf("Scan reflected err:%v", err)
+	}
+
+	if len(generated) != 3 || len(reflected) != 3 {
+		t.Fatalf("Scan got len generated:%d, reflected:%d", len(generated), len(reflected))
+	}
+	for i := range generated {
+		if !reflect.DeepEqual(generated[i], User(reflected[i])) {
+			t.Errorf("row %d generated:%#v != reflected:%#v", i, generated[i], reflected[i])
+		}
+	}
+
+	var one User
+	if err := b.QueryRow(`select * from user`).Scan(&one); err != nil {
+		t.Fatalf("Scan one err:%v", err)
+	}
+	if !reflect.DeepEqual(one, generated[0]) {
+		t.Errorf("Scan one got:%#v, want:%#v", one, generated[0])
+	}
+}
+
+func benchmarkScanSlice[T any](b *testing.B) {
+	db := browstest.NewDB()
+	defer db.Close()
+	bs := brows.New(db)
+
+	result := userResult(100)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		db.Push(result)
+		var out []T
+		if err := bs.Query(`select * from user`).Scan(&out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkScanSlice_Generated(b *testing.B) {
+	benchmarkScanSlice[User](b)
+}
+
+func BenchmarkScanSlice_Reflect(b *testing.B) {
+	benchmarkScanSlice[reflectUser](b)
+}
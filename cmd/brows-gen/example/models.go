@@ -0,0 +1,34 @@
+// Package example 用于测试 brows-gen 生成的代码, 并对比反射方式的性能
+package example
+
+import (
+	"database/sql"
+	"time"
+)
+
+//go:generate go run .. -type User
+
+type Status int8
+
+type Profile struct {
+	Email *string `db:"email"`
+	Phone string  `db:"phone"`
+}
+
+type Audit struct {
+	CreatedAt time.Time  `db:"created_at"`
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+type User struct {
+	ID     int64          `db:"id"`
+	Name   string         `db:"name"`
+	Age    uint8          `db:"age"`
+	Status Status         `db:"status"`
+	Note   sql.NullString `db:"note"`
+	Ignore string         `db:"-"`
+	hidden string
+
+	Audit
+	*Profile
+}
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	_tagLabel          = "db"
+	_tagOptionNullZero = "nullzero"
+)
+
+var errNoStruct = errors.New("brows-gen: no struct with db tags found")
+
+// step 字段访问路径中的一步
+type step struct {
+	name string
+	// 字段是否为结构体指针, 访问其内部字段前需分配对象
+	ptr bool
+	// 结构体指针字段的元素类型表达式, 用于 new(...)
+	typ string
+}
+
+// target 一个列对应的字段
+type target struct {
+	column string
+	path   []step
+}
+
+type model struct {
+	name    string
+	targets []target
+	// imports 生成的代码需要导入的包: 导入路径 -> 包名
+	imports map[string]string
+}
+
+type generator struct {
+	fset *token.FileSet
+	pkg  string
+	// 包内声明的结构体类型, 按声明顺序
+	names   []string
+	structs map[string]*ast.StructType
+	// 包内声明了 Scan 或 Value 方法的类型, 按叶子类型处理
+	leaves map[string]bool
+	// files 结构体所在文件的导入: 结构体名 -> 包名 -> 导入路径
+	files map[string]map[string]string
+	// 其他包的类型通过 go/types 从源码加载
+	importer *srcImporter
+}
+
+// parseDir 解析 dir 目录下的非测试 go 文件, output 为生成文件, 不参与解析
+func parseDir(dir, output string) (*generator, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		fset:    token.NewFileSet(),
+		structs: make(map[string]*ast.StructType),
+		leaves:  make(map[string]bool),
+		files:   make(map[string]map[string]string),
+	}
+	if g.importer, err = newImporter(g.fset, dir); err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") || filepath.Base(file) == filepath.Base(output) {
+			continue
+		}
+
+		f, err := parser.ParseFile(g.fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		g.pkg = f.Name.Name
+		imports := fileImports(f)
+
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok || ts.TypeParams != nil {
+						continue
+					}
+					if st, ok := ts.Type.(*ast.StructType); ok {
+						g.names = append(g.names, ts.Name.Name)
+						g.structs[ts.Name.Name] = st
+						g.files[ts.Name.Name] = imports
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv == nil || 1 != len(d.Recv.List) || ("Scan" != d.Name.Name && "Value" != d.Name.Name) {
+					continue
+				}
+				recv := d.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if ident, ok := recv.(*ast.Ident); ok {
+					g.leaves[ident.Name] = true
+				}
+			}
+		}
+	}
+
+	if "" == g.pkg {
+		return nil, fmt.Errorf("brows-gen: no go files in %s", dir)
+	}
+
+	return g, nil
+}
+
+// fileImports 返回文件 f 的导入: 包名 -> 导入路径. 未指定别名时以路径的最后一段作为包名
+func fileImports(f *ast.File) map[string]string {
+	out := make(map[string]string, len(f.Imports))
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if nil != spec.Name {
+			name = spec.Name.Name
+		}
+		out[name] = path
+	}
+
+	return out
+}
+
+// models 返回 types 中结构体的映射关系, types 为空时返回所有带 db tag 的结构体
+func (g *generator) models(types []string) ([]model, error) {
+	all := 0 == len(types)
+	if all {
+		types = g.names
+	}
+
+	var out []model
+	for _, name := range types {
+		st, ok := g.structs[name]
+		if !ok {
+			return nil, fmt.Errorf("brows-gen: struct %s not found in package %s", name, g.pkg)
+		}
+
+		m := model{name: name, imports: make(map[string]string)}
+		if err := g.walk(&m, st, g.files[name], nil, map[string]bool{name: true}, make(map[string]string)); err != nil {
+			return nil, fmt.Errorf("brows-gen: %s: %w", name, err)
+		}
+		if all && 0 == len(m.targets) {
+			continue
+		}
+		out = append(out, m)
+	}
+
+	if 0 == len(out) {
+		return nil, errNoStruct
+	}
+
+	return out, nil
+}
+
+// walk 按 brows mapping 的规则遍历结构体字段, imports 为 st 所在文件的导入. 其他包的结构体通过 walkType 遍历
+func (g *generator) walk(m *model, st *ast.StructType, imports map[string]string, prefix []step, visiting map[string]bool, tags map[string]string) error {
+	for _, field := range st.Fields.List {
+		typ, ptr := field.Type, false
+		if star, ok := typ.(*ast.StarExpr); ok {
+			typ, ptr = star.X, true
+		}
+
+		names := make([]string, 0, len(field.Names))
+		for _, v := range field.Names {
+			names = append(names, v.Name)
+		}
+		if 0 == len(names) {
+			// 匿名内嵌, 字段名为类型名
+			switch t := typ.(type) {
+			case *ast.Ident:
+				names = append(names, t.Name)
+			case *ast.SelectorExpr:
+				names = append(names, t.Sel.Name)
+			}
+		}
+
+		var tagValue, tagOptions string
+		if field.Tag != nil {
+			tag, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return err
+			}
+			tagValue, tagOptions, _ = strings.Cut(reflect.StructTag(tag).Get(_tagLabel), ",")
+		}
+
+		for _, name := range names {
+			if !ast.IsExported(name) {
+				continue
+			}
+
+			path := append(append([]step(nil), prefix...), step{name: name, ptr: ptr, typ: g.expr(typ)})
+			if inner, typeName, ok := g.nested(typ); ok {
+				if visiting[typeName] {
+					return fmt.Errorf("%s: recursive reference to %s", pathString(path), typeName)
+				}
+				if "" != typeName {
+					visiting[typeName] = true
+				}
+				if err := g.walk(m, inner, imports, path, visiting, tags); err != nil {
+					return err
+				}
+				delete(visiting, typeName)
+				continue
+			}
+
+			if sel, ok := typ.(*ast.SelectorExpr); ok {
+				rt, err := g.lookup(imports, sel)
+				if err != nil {
+					return fmt.Errorf("%s: %w", pathString(path), err)
+				}
+				if inner, ok := expandable(rt); ok {
+					if ptr {
+						path[len(path)-1].typ = types.TypeString(rt, m.qualifier)
+					}
+					if err := g.walkType(m, rt, inner, path, visiting, tags); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+
+			if err := m.add(path, tagValue, tagOptions, tags); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// walkType 遍历其他包的结构体 rt 的字段, st 为 rt 的底层结构体
+func (g *generator) walkType(m *model, rt types.Type, st *types.Struct, prefix []step, visiting map[string]bool, tags map[string]string) error {
+	typeName := ""
+	if _, ok := rt.(*types.Struct); !ok {
+		typeName = rt.String()
+	}
+	if visiting[typeName] {
+		return fmt.Errorf("%s: recursive reference to %s", pathString(prefix), typeName)
+	}
+	if "" != typeName {
+		visiting[typeName] = true
+		defer delete(visiting, typeName)
+	}
+
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Exported() {
+			continue
+		}
+
+		typ, ptr := field.Type(), false
+		if p, ok := typ.(*types.Pointer); ok {
+			typ, ptr = p.Elem(), true
+		}
+
+		path := append(append([]step(nil), prefix...), step{name: field.Name(), ptr: ptr})
+		if inner, ok := expandable(typ); ok {
+			if ptr {
+				path[len(path)-1].typ = types.TypeString(typ, m.qualifier)
+			}
+			if err := g.walkType(m, typ, inner, path, visiting, tags); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tagValue, tagOptions, _ := strings.Cut(reflect.StructTag(st.Tag(i)).Get(_tagLabel), ",")
+		if err := m.add(path, tagValue, tagOptions, tags); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// add 添加叶子字段 path 的映射, tag 为空或 - 时忽略
+func (m *model) add(path []step, tagValue, tagOptions string, tags map[string]string) error {
+	if "" == tagValue || "-" == tagValue {
+		return nil
+	}
+	if hasOption(tagOptions, _tagOptionNullZero) {
+		return fmt.Errorf("%s: tag option %s is not supported", pathString(path), _tagOptionNullZero)
+	}
+	if exist, ok := tags[tagValue]; ok {
+		return fmt.Errorf("%s: tag %q already used by %s", pathString(path), tagValue, exist)
+	}
+	tags[tagValue] = pathString(path)
+
+	path[len(path)-1].ptr = false
+	m.targets = append(m.targets, target{column: tagValue, path: path})
+	return nil
+}
+
+// qualifier 返回生成的代码中引用 pkg 使用的包名, 并记录需要导入的包
+func (m *model) qualifier(pkg *types.Package) string {
+	m.imports[pkg.Path()] = pkg.Name()
+	return pkg.Name()
+}
+
+// lookup 加载 sel 引用的其他包的类型, imports 为 sel 所在文件的导入
+func (g *generator) lookup(imports map[string]string, sel *ast.SelectorExpr) (types.Type, error) {
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return nil, fmt.Errorf("unsupported type %s", g.expr(sel))
+	}
+	path, ok := imports[ident.Name]
+	if !ok {
+		return nil, fmt.Errorf("unknown package %s", ident.Name)
+	}
+
+	pkg, err := g.importer.Import(path)
+	if err != nil {
+		return nil, fmt.Errorf("load package %s: %w", path, err)
+	}
+
+	obj, ok := pkg.Scope().Lookup(sel.Sel.Name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s not found in package %s", sel.Sel.Name, path)
+	}
+
+	return obj.Type(), nil
+}
+
+// expandable 判断其他包的类型 rt 是否为需要展开的结构体, 同 brows 的 nested: 非叶子类型的结构体
+func expandable(rt types.Type) (*types.Struct, bool) {
+	st, ok := rt.Underlying().(*types.Struct)
+	if !ok || "time.Time" == rt.String() {
+		return nil, false
+	}
+
+	// 值或指针接收者实现了 sql.Scanner 或 driver.Valuer 的类型为叶子类型
+	methods := types.NewMethodSet(types.NewPointer(rt))
+	for i := 0; i < methods.Len(); i++ {
+		if name := methods.At(i).Obj().Name(); "Scan" == name || "Value" == name {
+			return nil, false
+		}
+	}
+
+	return st, true
+}
+
+// nested 判断 typ 是否为需要展开的结构体: 包内非叶子的结构体类型, 或匿名结构体
+func (g *generator) nested(typ ast.Expr) (*ast.StructType, string, bool) {
+	switch t := typ.(type) {
+	case *ast.StructType:
+		return t, "", true
+	case *ast.Ident:
+		st, ok := g.structs[t.Name]
+		if !ok || g.leaves[t.Name] {
+			return nil, "", false
+		}
+		return st, t.Name, true
+	default:
+		return nil, "", false
+	}
+}
+
+func (g *generator) expr(e ast.Expr) string {
+	var buf bytes.Buffer
+	_ = printer.Fprint(&buf, g.fset, e)
+	return buf.String()
+}
+
+// generate 生成 models 的 ScanBrows 方法
+func (g *generator) generate(models []model) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by brows-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", g.pkg)
+	imports := map[string]string{"github.com/beanscc/brows": "brows"}
+	for _, m := range models {
+		for path, name := range m.imports {
+			imports[path] = name
+		}
+	}
+	if err := writeImports(&buf, imports); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(models, func(i, j int) bool { return models[i].name < models[j].name })
+	for _, m := range models {
+		fmt.Fprintf(&buf, "\n// ScanBrows 将 columns 对应的 Scan 目标写入 dest, 实现 brows.ColumnScanner\n")
+		fmt.Fprintf(&buf, "func (v *%s) ScanBrows(columns []string, dest []any) {\n", m.name)
+		buf.WriteString("for i, column := range columns {\n")
+		buf.WriteString("switch column {\n")
+		for _, t := range m.targets {
+			fmt.Fprintf(&buf, "case %s:\n", strconv.Quote(t.column))
+			selector := "v"
+			for _, s := range t.path {
+				selector += "." + s.name
+				if s.ptr {
+					fmt.Fprintf(&buf, "if %s == nil {\n%s = new(%s)\n}\n", selector, selector, s.typ)
+				}
+			}
+			fmt.Fprintf(&buf, "dest[i] = &%s\n", selector)
+		}
+		buf.WriteString("default:\ndest[i] = brows.Discard\n}\n}\n}\n")
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// writeImports 按路径排序写入 imports: 导入路径 -> 包名
+func writeImports(buf *bytes.Buffer, imports map[string]string) error {
+	paths := make([]string, 0, len(imports))
+	names := make(map[string]string, len(imports))
+	for path, name := range imports {
+		if exist, ok := names[name]; ok {
+			return fmt.Errorf("brows-gen: package name %s used by both %s and %s", name, exist, path)
+		}
+		names[name] = path
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	if 1 == len(paths) {
+		fmt.Fprintf(buf, "import %s\n", strconv.Quote(paths[0]))
+		return nil
+	}
+	buf.WriteString("import (\n")
+	for _, path := range paths {
+		if name := imports[path]; name != path[strings.LastIndex(path, "/")+1:] {
+			fmt.Fprintf(buf, "%s %s\n", name, strconv.Quote(path))
+		} else {
+			fmt.Fprintf(buf, "%s\n", strconv.Quote(path))
+		}
+	}
+	buf.WriteString(")\n")
+	return nil
+}
+
+func pathString(path []step) string {
+	names := make([]string, 0, len(path))
+	for _, v := range path {
+		names = append(names, v.name)
+	}
+
+	return strings.Join(names, ".")
+}
+
+func hasOption(options, option string) bool {
+	for _, v := range strings.Split(options, ",") {
+		if option == strings.TrimSpace(v) {
+			return true
+		}
+	}
+
+	return false
+}
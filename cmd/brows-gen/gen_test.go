@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate_Example(t *testing.T) {
+	g, err := parseDir("example", "brows_gen.go")
+	if err != nil {
+		t.Fatalf("parseDir err:%v", err)
+	}
+
+	models, err := g.models([]string{"User"})
+	if err != nil {
+		t.Fatalf("models err:%v", err)
+	}
+
+	got, err := g.generate(models)
+	if err != nil {
+		t.Fatalf("generate err:%v", err)
+	}
+
+	want, err := os.ReadFile(filepath.Join("example", "brows_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("generate not match example/brows_gen.go, run go generate ./cmd/brows-gen/example. got:\n%s", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	test := []struct {
+		name string
+		src  string
+		// files 其他文件, 如 go.mod 和其他包的源码
+		files   map[string]string
+		types   string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "all",
+			src: `package m
+
+type Money struct{ Cents int64 ` + "`db:\"cents\"`" + ` }
+
+func (m *Money) Scan(src any) error { return nil }
+
+type Order struct {
+	ID    int64 ` + "`db:\"id\"`" + `
+	Price Money ` + "`db:\"price\"`" + `
+	Extra *struct {
+		Memo string ` + "`db:\"memo\"`" + `
+	}
+}
+
+type NoTag struct{ A int }
+`,
+			want: []string{
+				"func (v *Order) ScanBrows(columns []string, dest []any) {",
+				"case \"price\":\n\t\t\tdest[i] = &v.Price",
+				"if v.Extra == nil {",
+				"dest[i] = &v.Extra.Memo",
+			},
+		},
+		{
+			name: "external",
+			src: `package m
+
+import (
+	"database/sql"
+	b "example.com/m/base"
+)
+
+type User struct {
+	b.Model
+	Name  string         ` + "`db:\"name\"`" + `
+	Note  sql.NullString ` + "`db:\"note\"`" + `
+	Audit *b.Audit
+}
+`,
+			files: map[string]string{
+				"go.mod": "module example.com/m\n\ngo 1.21\n",
+				"base/base.go": `package base
+
+import "time"
+
+type Model struct {
+	ID int64 ` + "`db:\"id\"`" + `
+	*Meta
+	hidden int64
+}
+
+type Meta struct {
+	Version int ` + "`db:\"version\"`" + `
+}
+
+type Audit struct {
+	CreatedAt time.Time ` + "`db:\"created_at\"`" + `
+}
+`,
+			},
+			want: []string{
+				"import (\n\t\"example.com/m/base\"\n\t\"github.com/beanscc/brows\"\n)",
+				"case \"id\":\n\t\t\tdest[i] = &v.Model.ID",
+				"if v.Model.Meta == nil {\n\t\t\t\tv.Model.Meta = new(base.Meta)\n\t\t\t}\n\t\t\tdest[i] = &v.Model.Meta.Version",
+				"case \"note\":\n\t\t\tdest[i] = &v.Note",
+				"if v.Audit == nil {\n\t\t\t\tv.Audit = new(base.Audit)\n\t\t\t}\n\t\t\tdest[i] = &v.Audit.CreatedAt",
+			},
+		},
+		{
+			name:    "external not found",
+			src:     "package m\n\nimport \"example.com/missing\"\n\ntype A struct {\n\tmissing.Model\n\tID int `db:\"id\"`\n}\n",
+			wantErr: "brows-gen: A: Model: load package example.com/missing",
+		},
+		{
+			name:    "not found",
+			src:     "package m\n\ntype A struct{}\n",
+			types:   "B",
+			wantErr: "struct B not found",
+		},
+		{
+			name:    "no struct",
+			src:     "package m\n\ntype A struct{ B int }\n",
+			wantErr: errNoStruct.Error(),
+		},
+		{
+			name:    "nullzero",
+			src:     "package m\n\ntype A struct{ B string `db:\"b,nullzero\"` }\n",
+			wantErr: "brows-gen: A: B: tag option nullzero is not supported",
+		},
+		{
+			name:    "conflict",
+			src:     "package m\n\ntype A struct{\n\tB string `db:\"b\"`\n\tC string `db:\"b\"`\n}\n",
+			wantErr: "brows-gen: A: C: tag \"b\" already used by B",
+		},
+		{
+			name:    "recursive",
+			src:     "package m\n\ntype A struct{\n\tID int `db:\"id\"`\n\tNext *A\n}\n",
+			wantErr: "brows-gen: A: Next: recursive reference to A",
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "m.go"), []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			for name, src := range tt.files {
+				if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := run(dir, "brows_gen.go", tt.types)
+			if "" != tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("run want err:%s, got:%v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run err:%v", err)
+			}
+
+			got, err := os.ReadFile(filepath.Join(dir, "brows_gen.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(got), want) {
+					t.Errorf("run output not contains:\n%s\ngot:\n%s", want, got)
+				}
+			}
+			if strings.Contains(string(got), "NoTag") {
+				t.Errorf("run output want NoTag skipped, got:\n%s", got)
+			}
+		})
+	}
+}
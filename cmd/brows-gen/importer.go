@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+)
+
+// srcImporter 从源码加载包, 在 dir 所在的模块中查找非标准库的包. 只需要类型声明, 忽略类型检查错误
+type srcImporter struct {
+	fset *token.FileSet
+	ctxt build.Context
+	std  types.Importer
+	// packages 已加载的包, 加载中的包为 nil
+	packages map[string]*types.Package
+}
+
+func newImporter(fset *token.FileSet, dir string) (*srcImporter, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ctxt := build.Default
+	// go list 在 ctxt.Dir 中执行, 以 dir 所在的模块解析导入路径
+	ctxt.Dir = abs
+	return &srcImporter{
+		fset:     fset,
+		ctxt:     ctxt,
+		std:      importer.ForCompiler(fset, "source", nil),
+		packages: make(map[string]*types.Package),
+	}, nil
+}
+
+func (p *srcImporter) Import(path string) (*types.Package, error) {
+	if pkg, ok := p.packages[path]; ok {
+		if nil == pkg {
+			return nil, fmt.Errorf("import cycle through %s", path)
+		}
+		return pkg, nil
+	}
+
+	bp, err := p.ctxt.Import(path, p.ctxt.Dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	if bp.Goroot {
+		pkg, err := p.std.Import(path)
+		if err != nil {
+			return nil, err
+		}
+		p.packages[path] = pkg
+		return pkg, nil
+	}
+
+	p.packages[path] = nil
+	var files []*ast.File
+	for _, name := range append(bp.GoFiles, bp.CgoFiles...) {
+		f, err := parser.ParseFile(p.fset, filepath.Join(bp.Dir, name), nil, 0)
+		if err != nil {
+			delete(p.packages, path)
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	conf := types.Config{Importer: p, FakeImportC: true, Error: func(error) {}}
+	pkg, _ := conf.Check(bp.ImportPath, p.fset, files, nil)
+	p.packages[path] = pkg
+	return pkg, nil
+}
@@ -0,0 +1,67 @@
+// brows-gen 为带 db tag 的结构体生成 ScanBrows 方法(实现 brows.ColumnScanner), 使 brows 的 Scan/ScanSlice 不再通过反射映射字段.
+//
+// usage:
+//
+//	//go:generate brows-gen -type User,Order
+//
+// flags:
+//
+//	-type   逗号分隔的结构体名称, 为空时生成包内所有带 db tag 的结构体
+//	-dir    包目录, 默认当前目录
+//	-output 生成的文件名, 默认 brows_gen.go
+//
+// 其他包的类型从源码加载(在 -dir 所在的模块中查找), 与 brows 的反射映射相同:
+// 叶子类型(如 time.Time, sql.NullString)按 tag 映射, 其他结构体展开其内部字段. 无法加载时报错.
+//
+// 限制:
+//   - 不支持 nullzero tag 选项
+//   - 通过 brows.RegisterLeafType 注册的类型不是叶子类型, 将展开其内部字段
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var (
+		types  = flag.String("type", "", "comma-separated struct names; default all structs with db tags")
+		dir    = flag.String("dir", ".", "package directory")
+		output = flag.String("output", "brows_gen.go", "output file name")
+	)
+	flag.Parse()
+
+	if err := run(*dir, *output, *types); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, output, types string) error {
+	g, err := parseDir(dir, output)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, v := range strings.Split(types, ",") {
+		if v = strings.TrimSpace(v); "" != v {
+			names = append(names, v)
+		}
+	}
+
+	models, err := g.models(names)
+	if err != nil {
+		return err
+	}
+
+	src, err := g.generate(models)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Table 表结构
+type Table struct {
+	Name    string
+	Comment string
+	Columns []Column
+}
+
+// Column 列结构
+type Column struct {
+	Name string
+	// Type 列类型, 小写, 如 int unsigned, varchar(255), tinyint(1)
+	Type     string
+	Nullable bool
+	Comment  string
+}
+
+// token 词法单元
+type token struct {
+	// text 标识符(已去掉反引号)、关键字、字符串(已去掉引号)或符号
+	text string
+	// quoted 是否为引号包围的字符串
+	quoted bool
+	// ident 是否为反引号包围的标识符, 如 `key`, 不作为关键字或符号
+	ident bool
+}
+
+// parseDDL 解析 src 中的 CREATE TABLE 语句, 其他语句将被忽略
+func parseDDL(src string) ([]Table, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Table
+	for _, stmt := range splitStatements(tokens) {
+		if !isCreateTable(stmt) {
+			continue
+		}
+
+		table, err := parseCreateTable(stmt)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, table)
+	}
+
+	return out, nil
+}
+
+// tokenize 拆分 src 为 token, 忽略注释
+func tokenize(src string) ([]token, error) {
+	var out []token
+	rs := []rune(src)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case '#' == r || ('-' == r && i+1 < len(rs) && '-' == rs[i+1]):
+			for i < len(rs) && '\n' != rs[i] {
+				i++
+			}
+		case '/' == r && i+1 < len(rs) && '*' == rs[i+1]:
+			end := strings.Index(string(rs[i+2:]), "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("brows-model: unterminated comment")
+			}
+			i += 2 + len([]rune(string(rs[i+2:])[:end])) + 2
+		case '`' == r || '\'' == r || '"' == r:
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(rs); j++ {
+				if rs[j] == r {
+					// 连续两个引号表示转义
+					if j+1 < len(rs) && rs[j+1] == r {
+						b.WriteRune(r)
+						j++
+						continue
+					}
+					break
+				}
+				if '\\' == rs[j] && '`' != r && j+1 < len(rs) {
+					j++
+				}
+				b.WriteRune(rs[j])
+			}
+			if j >= len(rs) {
+				return nil, fmt.Errorf("brows-model: unterminated quote %c", r)
+			}
+			out = append(out, token{text: b.String(), quoted: '`' != r, ident: '`' == r})
+			i = j + 1
+		case isIdentRune(r):
+			j := i
+			for j < len(rs) && isIdentRune(rs[j]) {
+				j++
+			}
+			out = append(out, token{text: string(rs[i:j])})
+			i = j
+		default:
+			out = append(out, token{text: string(r)})
+			i++
+		}
+	}
+
+	return out, nil
+}
+
+func isIdentRune(r rune) bool {
+	return '_' == r || '$' == r || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// splitStatements 按 ; 拆分语句
+func splitStatements(tokens []token) (out [][]token) {
+	var stmt []token
+	for _, t := range tokens {
+		if t.symbol(";") {
+			out = append(out, stmt)
+			stmt = nil
+			continue
+		}
+		stmt = append(stmt, t)
+	}
+	if len(stmt) > 0 {
+		out = append(out, stmt)
+	}
+
+	return out
+}
+
+// is 判断 t 是否为关键字 keyword(忽略大小写)
+func (t token) is(keyword string) bool {
+	return !t.quoted && !t.ident && strings.EqualFold(t.text, keyword)
+}
+
+// symbol 判断 t 是否为符号 s
+func (t token) symbol(s string) bool {
+	return !t.quoted && !t.ident && s == t.text
+}
+
+func isCreateTable(stmt []token) bool {
+	for i, t := range stmt {
+		if t.is("TABLE") {
+			return i > 0 && stmt[0].is("CREATE")
+		}
+		if t.is("(") {
+			return false
+		}
+	}
+
+	return false
+}
+
+// parseCreateTable 解析 CREATE [TEMPORARY] TABLE [IF NOT EXISTS] [db.]name (...) [options]
+func parseCreateTable(stmt []token) (Table, error) {
+	i := 0
+	for i < len(stmt) && !stmt[i].is("TABLE") {
+		i++
+	}
+	i++
+	if i+2 < len(stmt) && stmt[i].is("IF") && stmt[i+1].is("NOT") && stmt[i+2].is("EXISTS") {
+		i += 3
+	}
+	if i >= len(stmt) {
+		return Table{}, fmt.Errorf("brows-model: missing table name")
+	}
+
+	table := Table{Name: stmt[i].text}
+	i++
+	if i+1 < len(stmt) && stmt[i].symbol(".") {
+		table.Name = stmt[i+1].text
+		i += 2
+	}
+
+	if i >= len(stmt) || !stmt[i].symbol("(") {
+		return Table{}, fmt.Errorf("brows-model: table %s: missing column definitions", table.Name)
+	}
+
+	// 按顶层逗号拆分列定义
+	depth, start := 0, i+1
+	for i++; i < len(stmt); i++ {
+		switch {
+		case stmt[i].symbol("("):
+			depth++
+		case stmt[i].symbol(")") && depth > 0:
+			depth--
+		case (stmt[i].symbol(",") || stmt[i].symbol(")")) && 0 == depth:
+			if def := stmt[start:i]; len(def) > 0 && !isIndexDefinition(def) {
+				column, err := parseColumn(def)
+				if err != nil {
+					return Table{}, fmt.Errorf("brows-model: table %s: %w", table.Name, err)
+				}
+				table.Columns = append(table.Columns, column)
+			}
+			start = i + 1
+		}
+		if stmt[i].symbol(")") && 0 == depth && start == i+1 {
+			i++
+			break
+		}
+	}
+
+	// 表选项
+	for ; i < len(stmt); i++ {
+		if stmt[i].is("COMMENT") {
+			if i+1 < len(stmt) && stmt[i+1].symbol("=") {
+				i++
+			}
+			if i+1 < len(stmt) {
+				table.Comment = stmt[i+1].text
+			}
+			break
+		}
+	}
+
+	return table, nil
+}
+
+func isIndexDefinition(def []token) bool {
+	for _, keyword := range []string{"PRIMARY", "KEY", "INDEX", "UNIQUE", "CONSTRAINT", "FOREIGN", "FULLTEXT", "SPATIAL", "CHECK"} {
+		if def[0].is(keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseColumn 解析列定义: name type[(args)] [UNSIGNED] [ZEROFILL] [attributes]
+func parseColumn(def []token) (Column, error) {
+	if len(def) < 2 {
+		return Column{}, fmt.Errorf("invalid column definition")
+	}
+
+	column := Column{Name: def[0].text, Nullable: true}
+	typ := strings.ToLower(def[1].text)
+	i := 2
+	if i < len(def) && def[i].symbol("(") {
+		var args []string
+		for i++; i < len(def) && !def[i].symbol(")"); i++ {
+			if !def[i].symbol(",") {
+				args = append(args, def[i].text)
+			}
+		}
+		i++
+		typ += "(" + strings.Join(args, ",") + ")"
+	}
+	for ; i < len(def) && (def[i].is("UNSIGNED") || def[i].is("ZEROFILL") || def[i].is("PRECISION")); i++ {
+		if def[i].is("UNSIGNED") {
+			typ += " unsigned"
+		}
+	}
+	column.Type = typ
+
+	for ; i < len(def); i++ {
+		switch {
+		case def[i].is("NOT") && i+1 < len(def) && def[i+1].is("NULL"):
+			column.Nullable = false
+			i++
+		case def[i].is("PRIMARY"):
+			column.Nullable = false
+		case def[i].is("COMMENT") && i+1 < len(def):
+			column.Comment = def[i+1].text
+			i++
+		}
+	}
+
+	return column, nil
+}
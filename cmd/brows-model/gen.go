@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NullStyle 可为 NULL 的列对应的 Go 类型风格
+type NullStyle string
+
+const (
+	// NullPointer 使用指针, 如 *string
+	NullPointer NullStyle = "pointer"
+	// NullSQL 使用 database/sql 的 Null 类型, 如 sql.NullString, 无对应类型时使用指针
+	NullSQL NullStyle = "sql"
+)
+
+// goType 返回列类型对应的 Go 类型
+func goType(column Column, style NullStyle) string {
+	typ := baseType(column.Type)
+	if !column.Nullable || "[]byte" == typ {
+		return typ
+	}
+
+	if NullSQL == style {
+		if v, ok := _sqlNullTypes[typ]; ok {
+			return v
+		}
+	}
+
+	return "*" + typ
+}
+
+var _sqlNullTypes = map[string]string{
+	"string":    "sql.NullString",
+	"int64":     "sql.NullInt64",
+	"int32":     "sql.NullInt32",
+	"int16":     "sql.NullInt16",
+	"uint8":     "sql.NullByte",
+	"float64":   "sql.NullFloat64",
+	"bool":      "sql.NullBool",
+	"time.Time": "sql.NullTime",
+}
+
+// baseType 返回 MySQL 列类型对应的非 NULL Go 类型
+func baseType(columnType string) string {
+	name, args, _ := strings.Cut(columnType, "(")
+	name, _, _ = strings.Cut(name, " ")
+	args, _, _ = strings.Cut(args, ")")
+	unsigned := strings.Contains(columnType, "unsigned")
+
+	integer := func(signedType, unsignedType string) string {
+		if unsigned {
+			return unsignedType
+		}
+		return signedType
+	}
+
+	switch name {
+	case "tinyint":
+		if "1" == args {
+			return "bool"
+		}
+		return integer("int8", "uint8")
+	case "bool", "boolean":
+		return "bool"
+	case "smallint":
+		return integer("int16", "uint16")
+	case "mediumint", "int", "integer":
+		return integer("int32", "uint32")
+	case "bigint":
+		return integer("int64", "uint64")
+	case "year":
+		return "int16"
+	case "float":
+		return "float32"
+	case "double", "real":
+		return "float64"
+	case "date", "datetime", "timestamp":
+		return "time.Time"
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob", "bit":
+		return "[]byte"
+	default:
+		// char, varchar, text, enum, set, json, time 以及 decimal 等(避免精度丢失)
+		return "string"
+	}
+}
+
+// generate 生成 tables 对应的结构体
+func generate(pkg string, tables []Table, style NullStyle) ([]byte, error) {
+	var body bytes.Buffer
+	imports := make(map[string]bool)
+	for _, table := range tables {
+		name := goName(table.Name)
+		if "" != table.Comment {
+			fmt.Fprintf(&body, "\n// %s %s\n", name, oneLine(table.Comment))
+		} else {
+			fmt.Fprintf(&body, "\n// %s 表 %s\n", name, table.Name)
+		}
+		fmt.Fprintf(&body, "type %s struct {\n", name)
+		for _, column := range table.Columns {
+			typ := goType(column, style)
+			if strings.Contains(typ, "time.") {
+				imports["time"] = true
+			}
+			if strings.Contains(typ, "sql.") {
+				imports["database/sql"] = true
+			}
+
+			if "" != column.Comment {
+				fmt.Fprintf(&body, "// %s\n", oneLine(column.Comment))
+			}
+			fmt.Fprintf(&body, "%s %s `db:%s`\n", goName(column.Name), typ, strconv.Quote(column.Name))
+		}
+		body.WriteString("}\n")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by brows-model. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n", pkg)
+	if len(imports) > 0 {
+		paths := make([]string, 0, len(imports))
+		for k := range imports {
+			paths = append(paths, strconv.Quote(k))
+		}
+		sort.Strings(paths)
+		fmt.Fprintf(&buf, "\nimport (\n%s\n)\n", strings.Join(paths, "\n"))
+	}
+	buf.Write(body.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+// _initialisms 常见缩写, 转换为 Go 名称时全部大写
+var _initialisms = map[string]bool{
+	"api": true, "ascii": true, "cpu": true, "css": true, "dns": true, "html": true, "http": true, "https": true,
+	"id": true, "ip": true, "json": true, "sql": true, "ssh": true, "tcp": true, "ttl": true, "udp": true,
+	"ui": true, "uid": true, "uuid": true, "uri": true, "url": true, "xml": true,
+}
+
+// goName 转换 snake_case 名称为导出的 Go 名称, 如 user_id -> UserID
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		lower := strings.ToLower(word)
+		if _initialisms[lower] {
+			b.WriteString(strings.ToUpper(lower))
+			continue
+		}
+
+		rs := []rune(word)
+		b.WriteString(strings.ToUpper(string(rs[0])) + string(rs[1:]))
+	}
+
+	out := b.String()
+	if "" == out || unicode.IsDigit([]rune(out)[0]) {
+		out = "X" + out
+	}
+
+	return out
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
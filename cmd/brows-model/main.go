@@ -0,0 +1,145 @@
+// brows-model 根据 CREATE TABLE 语句或 MySQL 的 information_schema 生成可用于 brows Scan/ScanSlice 的结构体.
+//
+// usage:
+//
+//	brows-model -pkg model -output model/tables.go schema.sql
+//	brows-model -dsn 'user:pass@tcp(127.0.0.1:3306)/test' -tables user,order -pkg model -output model/tables.go
+//
+// flags:
+//
+//	-dsn    MySQL dsn, 设置时从 information_schema 读取表结构, 否则从参数指定的 DDL 文件读取
+//	-schema 库名, 默认 dsn 中的库
+//	-tables 逗号分隔的表名, 为空时生成所有表
+//	-pkg    包名, 默认 model
+//	-output 生成的文件, 为空时输出到标准输出
+//	-null   可为 NULL 的列的类型风格: pointer(*string) 或 sql(sql.NullString), 默认 pointer
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+func main() {
+	var (
+		dsn    = flag.String("dsn", "", "MySQL dsn; read tables from information_schema when set")
+		schema = flag.String("schema", "", "database name; default the database in dsn")
+		tables = flag.String("tables", "", "comma-separated table names; default all tables")
+		pkg    = flag.String("pkg", "model", "package name")
+		output = flag.String("output", "", "output file; default stdout")
+		null   = flag.String("null", string(NullPointer), "go type style of nullable columns: pointer or sql")
+	)
+	flag.Parse()
+
+	cfg := config{
+		dsn:    *dsn,
+		schema: *schema,
+		files:  flag.Args(),
+		pkg:    *pkg,
+		output: *output,
+		null:   NullStyle(*null),
+	}
+	for _, v := range strings.Split(*tables, ",") {
+		if v = strings.TrimSpace(v); "" != v {
+			cfg.tables = append(cfg.tables, v)
+		}
+	}
+
+	if err := run(context.Background(), cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+type config struct {
+	dsn    string
+	schema string
+	files  []string
+	tables []string
+	pkg    string
+	output string
+	null   NullStyle
+}
+
+func run(ctx context.Context, cfg config) error {
+	if NullPointer != cfg.null && NullSQL != cfg.null {
+		return fmt.Errorf("brows-model: unknown null style %q", cfg.null)
+	}
+
+	var (
+		tables []Table
+		err    error
+	)
+	if "" != cfg.dsn {
+		tables, err = loadDSN(ctx, cfg.dsn, cfg.schema, cfg.tables)
+	} else {
+		tables, err = loadFiles(cfg.files, cfg.tables)
+	}
+	if err != nil {
+		return err
+	}
+	if 0 == len(tables) {
+		return fmt.Errorf("brows-model: no table found")
+	}
+
+	src, err := generate(cfg.pkg, tables, cfg.null)
+	if err != nil {
+		return err
+	}
+
+	if "" == cfg.output {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+
+	return os.WriteFile(cfg.output, src, 0o644)
+}
+
+func loadDSN(ctx context.Context, dsn, schema string, tables []string) ([]Table, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return loadSchema(ctx, db, schema, tables)
+}
+
+// loadFiles 读取 DDL 文件中 tables 的表结构, tables 为空时读取所有表
+func loadFiles(files, tables []string) ([]Table, error) {
+	if 0 == len(files) {
+		return nil, fmt.Errorf("brows-model: -dsn or DDL files required")
+	}
+
+	filter := make(map[string]bool, len(tables))
+	for _, v := range tables {
+		filter[v] = true
+	}
+
+	var out []Table
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed, err := parseDDL(string(src))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		for _, v := range parsed {
+			if len(filter) > 0 && !filter[v.Name] {
+				continue
+			}
+			out = append(out, v)
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/beanscc/brows/browstest"
+)
+
+const _ddl = "-- users\n" +
+	"DROP TABLE IF EXISTS `user`;\n" +
+	"CREATE TABLE IF NOT EXISTS `test`.`user` (\n" +
+	"  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT 'user id',\n" +
+	"  `name` varchar(64) NOT NULL DEFAULT '' COMMENT 'name, ''nick'' name',\n" +
+	"  `age` tinyint(3) unsigned DEFAULT NULL,\n" +
+	"  `is_admin` tinyint(1) NOT NULL DEFAULT '0',\n" +
+	"  `balance` decimal(10,2) DEFAULT NULL,\n" +
+	"  `score` double precision,\n" +
+	"  `avatar` blob,\n" +
+	"  /* inline comment */\n" +
+	"  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+	"  `deleted_at` datetime DEFAULT NULL,\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  UNIQUE KEY `uk_name` (`name`, `age`),\n" +
+	"  KEY `idx_created` (`created_at`)\n" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='users';\n" +
+	"\n" +
+	"create table order_item (id int primary key, sku_url varchar(255));\n"
+
+func TestParseDDL(t *testing.T) {
+	got, err := parseDDL(_ddl)
+	if err != nil {
+		t.Fatalf("parseDDL err:%v", err)
+	}
+
+	want := []Table{
+		{
+			Name:    "user",
+			Comment: "users",
+			Columns: []Column{
+				{Name: "id", Type: "bigint unsigned", Comment: "user id"},
+				{Name: "name", Type: "varchar(64)", Comment: "name, 'nick' name"},
+				{Name: "age", Type: "tinyint(3) unsigned", Nullable: true},
+				{Name: "is_admin", Type: "tinyint(1)"},
+				{Name: "balance", Type: "decimal(10,2)", Nullable: true},
+				{Name: "score", Type: "double", Nullable: true},
+				{Name: "avatar", Type: "blob", Nullable: true},
+				{Name: "created_at", Type: "datetime"},
+				{Name: "deleted_at", Type: "datetime", Nullable: true},
+			},
+		},
+		{
+			Name: "order_item",
+			Columns: []Column{
+				{Name: "id", Type: "int"},
+				{Name: "sku_url", Type: "varchar(255)", Nullable: true},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDDL got:\n%#v\nwant:\n%#v", got, want)
+	}
+}
+
+func TestParseDDL_KeywordColumns(t *testing.T) {
+	got, err := parseDDL("CREATE TABLE `config` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `key` varchar(64) NOT NULL,\n" +
+		"  `check` tinyint(1),\n" +
+		"  `index` int,\n" +
+		"  `primary` int,\n" +
+		"  `unique` int,\n" +
+		"  `(` int,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `key` (`key`)\n" +
+		")")
+	if err != nil {
+		t.Fatalf("parseDDL err:%v", err)
+	}
+
+	want := []Table{{
+		Name: "config",
+		Columns: []Column{
+			{Name: "id", Type: "int"},
+			{Name: "key", Type: "varchar(64)"},
+			{Name: "check", Type: "tinyint(1)", Nullable: true},
+			{Name: "index", Type: "int", Nullable: true},
+			{Name: "primary", Type: "int", Nullable: true},
+			{Name: "unique", Type: "int", Nullable: true},
+			{Name: "(", Type: "int", Nullable: true},
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDDL got:\n%#v\nwant:\n%#v", got, want)
+	}
+}
+
+func TestGoType(t *testing.T) {
+	test := []struct {
+		column Column
+		style  NullStyle
+		want   string
+	}{
+		{column: Column{Type: "bigint unsigned"}, want: "uint64"},
+		{column: Column{Type: "int(11)"}, want: "int32"},
+		{column: Column{Type: "tinyint(1)"}, want: "bool"},
+		{column: Column{Type: "tinyint(4)", Nullable: true}, style: NullPointer, want: "*int8"},
+		{column: Column{Type: "tinyint(3) unsigned", Nullable: true}, style: NullSQL, want: "sql.NullByte"},
+		{column: Column{Type: "varchar(255)", Nullable: true}, style: NullSQL, want: "sql.NullString"},
+		{column: Column{Type: "int unsigned", Nullable: true}, style: NullSQL, want: "*uint32"},
+		{column: Column{Type: "decimal(10,2)"}, want: "string"},
+		{column: Column{Type: "datetime", Nullable: true}, style: NullSQL, want: "sql.NullTime"},
+		{column: Column{Type: "longblob", Nullable: true}, style: NullPointer, want: "[]byte"},
+		{column: Column{Type: "json"}, want: "string"},
+	}
+
+	for _, tt := range test {
+		if got := goType(tt.column, tt.style); got != tt.want {
+			t.Errorf("goType(%s) got:%s, want:%s", tt.column.Type, got, tt.want)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	test := map[string]string{
+		"id":         "ID",
+		"user_id":    "UserID",
+		"sku_url":    "SkuURL",
+		"created_at": "CreatedAt",
+		"order-item": "OrderItem",
+		"2fa":        "X2fa",
+	}
+
+	for name, want := range test {
+		if got := goName(name); got != want {
+			t.Errorf("goName(%s) got:%s, want:%s", name, got, want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	tables, err := parseDDL(_ddl)
+	if err != nil {
+		t.Fatalf("parseDDL err:%v", err)
+	}
+
+	got, err := generate("model", tables, NullSQL)
+	if err != nil {
+		t.Fatalf("generate err:%v", err)
+	}
+
+	wants := []string{
+		"package model",
+		"import ( \"database/sql\" \"time\" )",
+		"// User users type User struct {",
+		"// user id ID uint64 `db:\"id\"`",
+		"Age sql.NullByte `db:\"age\"`",
+		"IsAdmin bool `db:\"is_admin\"`",
+		"Balance sql.NullString `db:\"balance\"`",
+		"CreatedAt time.Time `db:\"created_at\"`",
+		"DeletedAt sql.NullTime `db:\"deleted_at\"`",
+		"// OrderItem 表 order_item type OrderItem struct {",
+	}
+	// 忽略 gofmt 的对齐空白
+	normalized := strings.Join(strings.Fields(string(got)), " ")
+	for _, want := range wants {
+		if !strings.Contains(normalized, want) {
+			t.Errorf("generate not contains:\n%s\ngot:\n%s", want, got)
+		}
+	}
+}
+
+func TestLoadSchema(t *testing.T) {
+	db := browstest.NewDB(
+		browstest.NewResult("name").AddRow("test"),
+		browstest.NewResult("TABLE_NAME", "TABLE_COMMENT").AddRow("order", "").AddRow("user", "users"),
+		browstest.NewResult("TABLE_NAME", "COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_COMMENT").
+			AddRow("order", "id", "bigint", "NO", "").
+			AddRow("user", "id", "bigint unsigned", "NO", "user id").
+			AddRow("user", "age", "int", "YES", ""),
+	)
+	defer db.Close()
+
+	got, err := loadSchema(context.Background(), db, "", []string{"user"})
+	if err != nil {
+		t.Fatalf("loadSchema err:%v", err)
+	}
+
+	want := []Table{{
+		Name:    "user",
+		Comment: "users",
+		Columns: []Column{
+			{Name: "id", Type: "bigint unsigned", Comment: "user id"},
+			{Name: "age", Type: "int", Nullable: true},
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loadSchema got:%#v, want:%#v", got, want)
+	}
+
+	calls := db.Calls()
+	if len(calls) != 3 || !reflect.DeepEqual(calls[1].Args, []any{"test"}) {
+		t.Errorf("loadSchema got unexpected calls:%v", calls)
+	}
+}
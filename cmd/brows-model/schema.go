@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+
+	"github.com/beanscc/brows"
+)
+
+// loadSchema 通过 information_schema 读取 schema 中 tables 的表结构, schema 为空时使用当前库, tables 为空时读取所有表
+func loadSchema(ctx context.Context, q brows.Query, schema string, tables []string) ([]Table, error) {
+	// MySQL 8 中 information_schema 的列名为大写
+	b := brows.New(q, brows.WithMatchMode(brows.MatchCaseInsensitive))
+	if "" == schema {
+		var current struct {
+			Name string `db:"name"`
+		}
+		if err := b.QueryRowContext(ctx, `select database() as name`).Scan(&current); err != nil {
+			return nil, err
+		}
+		schema = current.Name
+	}
+
+	var infos []struct {
+		Name    string `db:"table_name"`
+		Comment string `db:"table_comment"`
+	}
+	err := b.QueryContext(ctx, `select table_name, table_comment from information_schema.tables
+where table_schema = ? and table_type = 'BASE TABLE' order by table_name`, schema).Scan(&infos)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []struct {
+		Table    string `db:"table_name"`
+		Name     string `db:"column_name"`
+		Type     string `db:"column_type"`
+		Nullable string `db:"is_nullable"`
+		Comment  string `db:"column_comment"`
+	}
+	err = b.QueryContext(ctx, `select table_name, column_name, column_type, is_nullable, column_comment
+from information_schema.columns where table_schema = ? order by table_name, ordinal_position`, schema).Scan(&columns)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := make(map[string]bool, len(tables))
+	for _, v := range tables {
+		filter[v] = true
+	}
+
+	var out []Table
+	index := make(map[string]int)
+	for _, v := range infos {
+		if len(filter) > 0 && !filter[v.Name] {
+			continue
+		}
+		index[v.Name] = len(out)
+		out = append(out, Table{Name: v.Name, Comment: v.Comment})
+	}
+	for _, v := range columns {
+		i, ok := index[v.Table]
+		if !ok {
+			continue
+		}
+		out[i].Columns = append(out[i].Columns, Column{
+			Name:     v.Name,
+			Type:     v.Type,
+			Nullable: "YES" == v.Nullable,
+			Comment:  v.Comment,
+		})
+	}
+
+	return out, nil
+}
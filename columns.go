@@ -0,0 +1,128 @@
+package brows
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ColumnsOption Columns 的配置项
+type ColumnsOption func(o *columnsOptions)
+
+type columnsOptions struct {
+	alias string
+	quote func(string) string
+}
+
+// ColumnsAlias 为列名添加表别名(或表名)前缀, 如 u.id
+func ColumnsAlias(alias string) ColumnsOption {
+	return func(o *columnsOptions) {
+		o.alias = alias
+	}
+}
+
+// ColumnsQuote 使用 quote 引用表别名和列名, 如 QuoteMySQL, QuoteANSI
+func ColumnsQuote(quote func(string) string) ColumnsOption {
+	return func(o *columnsOptions) {
+		o.quote = quote
+	}
+}
+
+// QuoteMySQL 使用反引号引用标识符, 如 `id`
+func QuoteMySQL(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// QuoteANSI 使用双引号引用标识符, 如 "id"
+func QuoteANSI(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Columns 返回结构体 v 中映射的列名(即 Scan 匹配的 tag), 按字段声明顺序排列. v 可以是 struct, *struct 或其 reflect.Type.
+// v 不是结构体时返回 nil; tag 重复时 panic, 同 Scan.
+//
+// example:
+//
+//	type User struct {
+//		ID   int64  `db:"id"`
+//		Name string `db:"name"`
+//	}
+//
+//	Columns(User{})                                           // [id name]
+//	Columns(User{}, ColumnsAlias("u"), ColumnsQuote(QuoteMySQL)) // [`u`.`id` `u`.`name`]
+func Columns(v any, opts ...ColumnsOption) []string {
+	rt, ok := v.(reflect.Type)
+	if !ok {
+		rt = reflect.TypeOf(v)
+	}
+	if rt == nil {
+		return nil
+	}
+	if reflect.Pointer == rt.Kind() {
+		rt = rt.Elem()
+	}
+	if reflect.Struct != rt.Kind() {
+		return nil
+	}
+
+	o := &columnsOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	m := typeMapping(rt)
+	fields := make([]structField, 0, len(m))
+	for _, f := range m {
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return lessIndex(fields[i].index, fields[j].index)
+	})
+
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		out = append(out, o.column(f.column))
+	}
+
+	return out
+}
+
+// ColumnsSQL 返回结构体 T 映射的列名, 以逗号分隔, 可直接用于 SELECT 语句. alias 非空时为列名添加表别名前缀
+//
+// example:
+//
+//	query := "select " + ColumnsSQL[User]("u") + " from user u where u.id = ?" // select u.id,u.name from user u where u.id = ?
+func ColumnsSQL[T any](alias string, opts ...ColumnsOption) string {
+	if "" != alias {
+		opts = append([]ColumnsOption{ColumnsAlias(alias)}, opts...)
+	}
+
+	return strings.Join(Columns(reflect.TypeOf((*T)(nil)).Elem(), opts...), ",")
+}
+
+func (o *columnsOptions) column(name string) string {
+	alias := o.alias
+	if o.quote != nil {
+		name = o.quote(name)
+		if "" != alias {
+			alias = o.quote(alias)
+		}
+	}
+
+	if "" == alias {
+		return name
+	}
+
+	return alias + "." + name
+}
+
+// lessIndex 按字段声明顺序比较字段索引
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+
+	return len(a) < len(b)
+}
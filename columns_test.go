@@ -0,0 +1,67 @@
+package brows
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestColumns(t *testing.T) {
+	type Audit struct {
+		CreatedAt time.Time `db:"created_at"`
+		UpdatedAt time.Time `db:"updated_at"`
+	}
+
+	type User struct {
+		ID     int64  `db:"id"`
+		Name   string `db:"name"`
+		Ignore string `db:"-"`
+		NoTag  string
+		*Audit
+		Age int `db:"age"`
+	}
+
+	test := []struct {
+		name string
+		v    any
+		opts []ColumnsOption
+		want []string
+	}{
+		{name: "struct", v: User{}, want: []string{"id", "name", "created_at", "updated_at", "age"}},
+		{name: "pointer", v: &User{}, want: []string{"id", "name", "created_at", "updated_at", "age"}},
+		{name: "type", v: reflect.TypeOf(User{}), want: []string{"id", "name", "created_at", "updated_at", "age"}},
+		{name: "alias", v: User{}, opts: []ColumnsOption{ColumnsAlias("u")},
+			want: []string{"u.id", "u.name", "u.created_at", "u.updated_at", "u.age"}},
+		{name: "quote", v: User{}, opts: []ColumnsOption{ColumnsQuote(QuoteMySQL)},
+			want: []string{"`id`", "`name`", "`created_at`", "`updated_at`", "`age`"}},
+		{name: "alias quote", v: User{}, opts: []ColumnsOption{ColumnsAlias("u"), ColumnsQuote(QuoteANSI)},
+			want: []string{`"u"."id"`, `"u"."name"`, `"u"."created_at"`, `"u"."updated_at"`, `"u"."age"`}},
+		{name: "not struct", v: 1, want: nil},
+		{name: "nil", v: nil, want: nil},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Columns(tt.v, tt.opts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Columns got:%v, want:%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnsSQL(t *testing.T) {
+	type User struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+
+	if got := ColumnsSQL[User](""); got != "id,name" {
+		t.Errorf("ColumnsSQL got:%s", got)
+	}
+	if got := ColumnsSQL[User]("u"); got != "u.id,u.name" {
+		t.Errorf("ColumnsSQL got:%s", got)
+	}
+	if got := ColumnsSQL[*User]("u", ColumnsQuote(QuoteMySQL)); got != "`u`.`id`,`u`.`name`" {
+		t.Errorf("ColumnsSQL got:%s", got)
+	}
+}
@@ -0,0 +1,174 @@
+package brows
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var ErrColumnType = errors.New("brows: column type not match field")
+
+// WithColumnTypeCheck 开启 Scan 前的列类型检查.
+//
+// 开启后, Row.Scan/Rows.Scan 在复制记录前，会对比每个映射字段的类型和 Rows.ColumnTypes() 中对应列的 ScanType() 和 Nullable(),
+// 若不兼容则返回 ErrColumnType 错误, 而不是 database/sql 在复制时返回的转换错误, 如:
+//
+//	brows: column type not match field: column age is nullable but field Age is uint
+//
+// 检查依赖驱动提供的列类型信息，驱动未提供的信息不做检查
+func WithColumnTypeCheck() Option {
+	return func(o *options) {
+		o.checkColumnTypes = true
+	}
+}
+
+// columnTypeClass 列/字段类型的大致分类
+type columnTypeClass uint8
+
+const (
+	classUnknown columnTypeClass = iota
+	classNumeric
+	classBool
+	classTime
+	classText
+)
+
+var _timeType = reflect.TypeOf(time.Time{})
+
+// checkRowsColumnTypes 检查 fields 和 rows 的列类型是否兼容
+func checkRowsColumnTypes(rows *sql.Rows, fields structFields, rt reflect.Type) error {
+	cts, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+
+	types := make([]columnType, 0, len(cts))
+	for _, v := range cts {
+		types = append(types, v)
+	}
+
+	return checkColumnTypes(types, fields, rt)
+}
+
+// columnType *sql.ColumnType 中类型检查用到的方法
+type columnType interface {
+	Nullable() (nullable, ok bool)
+	ScanType() reflect.Type
+	DatabaseTypeName() string
+}
+
+// checkColumnTypes 检查 fields 中映射字段的类型和列类型是否兼容, rt 为字段所在结构体类型
+func checkColumnTypes(cts []columnType, fields structFields, rt reflect.Type) error {
+	if len(cts) != len(fields) {
+		return nil
+	}
+
+	for i, f := range fields {
+		if f.ignore {
+			continue
+		}
+
+		ct := cts[i]
+		ft := f.value.Type()
+		if nullable, ok := ct.Nullable(); ok && nullable && !f.nullZero && !nullSafe(ft) {
+			return fmt.Errorf("%w: column %s is nullable but field %s is %s", ErrColumnType, f.column, fieldName(rt, f.index), ft)
+		}
+
+		st := ct.ScanType()
+		if st == nil {
+			continue
+		}
+
+		column, field := classOf(st), classOf(ft)
+		if columnTypeCompatible(column, field) {
+			continue
+		}
+
+		return fmt.Errorf("%w: column %s is %s but field %s is %s", ErrColumnType, f.column,
+			strings.ToLower(ct.DatabaseTypeName()), fieldName(rt, f.index), ft)
+	}
+
+	return nil
+}
+
+// columnTypeCompatible 列类型分类和字段类型分类是否兼容.
+// 文本列可以转换为任意类型，文本字段可以接收任意类型, 只有数值/布尔与时间之间不兼容
+func columnTypeCompatible(column, field columnTypeClass) bool {
+	switch {
+	case classUnknown == column || classUnknown == field:
+		return true
+	case classText == column || classText == field:
+		return true
+	case classTime == column:
+		return classTime == field
+	case classTime == field:
+		return false
+	default:
+		return true
+	}
+}
+
+// nullSafe 判断 rt 类型的字段能否接收 NULL
+func nullSafe(rt reflect.Type) bool {
+	if rt.Implements(_scannerType) || reflect.PointerTo(rt).Implements(_scannerType) {
+		return true
+	}
+
+	switch rt.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return true
+	case reflect.Slice:
+		return reflect.Uint8 == rt.Elem().Kind()
+	default:
+		return false
+	}
+}
+
+// classOf 返回 rt 的类型分类. sql.NullInt64 等形如 struct{ V T; Valid bool } 的类型按 T 分类
+func classOf(rt reflect.Type) columnTypeClass {
+	for reflect.Pointer == rt.Kind() {
+		rt = rt.Elem()
+	}
+
+	switch rt.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return classNumeric
+	case reflect.Bool:
+		return classBool
+	case reflect.String:
+		return classText
+	case reflect.Slice:
+		if reflect.Uint8 == rt.Elem().Kind() {
+			return classText
+		}
+	case reflect.Struct:
+		if rt.ConvertibleTo(_timeType) {
+			return classTime
+		}
+		if 2 == rt.NumField() && "Valid" == rt.Field(1).Name && reflect.Bool == rt.Field(1).Type.Kind() {
+			return classOf(rt.Field(0).Type)
+		}
+	}
+
+	return classUnknown
+}
+
+// fieldName 返回 index 对应字段在 rt 中的名称路径, 如 Created.Operator
+func fieldName(rt reflect.Type, index []int) string {
+	names := make([]string, 0, len(index))
+	for _, x := range index {
+		if reflect.Pointer == rt.Kind() {
+			rt = rt.Elem()
+		}
+		field := rt.Field(x)
+		names = append(names, field.Name)
+		rt = field.Type
+	}
+
+	return strings.Join(names, ".")
+}
@@ -0,0 +1,105 @@
+package brows
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeColumnType struct {
+	nullable   bool
+	noNullable bool
+	scanType   reflect.Type
+	name       string
+}
+
+func (ct fakeColumnType) Nullable() (nullable, ok bool) { return ct.nullable, !ct.noNullable }
+func (ct fakeColumnType) ScanType() reflect.Type        { return ct.scanType }
+func (ct fakeColumnType) DatabaseTypeName() string      { return ct.name }
+
+func TestCheckColumnTypes(t *testing.T) {
+	type Created struct {
+		At time.Time `db:"created_at"`
+	}
+
+	type User struct {
+		ID   int64          `db:"id"`
+		Age  uint           `db:"age"`
+		Name *string        `db:"name"`
+		Note sql.NullString `db:"note"`
+		Created
+	}
+
+	var (
+		int64Type     = reflect.TypeOf(int64(0))
+		nullInt64Type = reflect.TypeOf(sql.NullInt64{})
+		nullTimeType  = reflect.TypeOf(sql.NullTime{})
+		stringType    = reflect.TypeOf("")
+	)
+
+	test := []struct {
+		name    string
+		columns []string
+		cts     []columnType
+		wantErr string
+	}{
+		{
+			name:    "compatible",
+			columns: []string{"id", "age", "name", "note", "created_at", "other"},
+			cts: []columnType{
+				fakeColumnType{scanType: int64Type, name: "BIGINT"},
+				fakeColumnType{scanType: stringType, name: "VARCHAR"},
+				fakeColumnType{nullable: true, scanType: stringType, name: "VARCHAR"},
+				fakeColumnType{nullable: true, scanType: stringType, name: "VARCHAR"},
+				fakeColumnType{scanType: nullTimeType, name: "DATETIME"},
+				fakeColumnType{nullable: true, scanType: int64Type, name: "BIGINT"},
+			},
+		},
+		{
+			name:    "nullable unknown",
+			columns: []string{"age"},
+			cts:     []columnType{fakeColumnType{nullable: true, noNullable: true, name: "INT"}},
+		},
+		{
+			name:    "nullable",
+			columns: []string{"id", "age"},
+			cts: []columnType{
+				fakeColumnType{scanType: int64Type, name: "BIGINT"},
+				fakeColumnType{nullable: true, scanType: nullInt64Type, name: "INT"},
+			},
+			wantErr: "brows: column type not match field: column age is nullable but field Age is uint",
+		},
+		{
+			name:    "time into numeric",
+			columns: []string{"id"},
+			cts:     []columnType{fakeColumnType{scanType: nullTimeType, name: "DATETIME"}},
+			wantErr: "brows: column type not match field: column id is datetime but field ID is int64",
+		},
+		{
+			name:    "numeric into time",
+			columns: []string{"created_at"},
+			cts:     []columnType{fakeColumnType{scanType: int64Type, name: "INT"}},
+			wantErr: "brows: column type not match field: column created_at is int but field Created.At is time.Time",
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			var dest User
+			fields := mappingByColumns(tt.columns, reflect.ValueOf(&dest), newOptions())
+			err := checkColumnTypes(tt.cts, fields, reflect.TypeOf(dest))
+			if "" == tt.wantErr {
+				if err != nil {
+					t.Errorf("checkColumnTypes err:%v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrColumnType) || err.Error() != tt.wantErr {
+				t.Errorf("checkColumnTypes want err:%s, got:%v", tt.wantErr, err)
+			}
+		})
+	}
+}
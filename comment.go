@@ -0,0 +1,146 @@
+package brows
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// SQLComment sqlcommenter 格式的 SQL 注释配置, 如 select 1 /*app='api',route='%2Fusers'*/
+type SQLComment struct {
+	// Tags 静态标签, 如 app, db_driver
+	Tags map[string]string
+	// Context 返回 ctx 中的标签, 如 route, traceparent, 与 WithCommentTags 设置的标签合并
+	Context func(ctx context.Context) map[string]string
+	// Prepend 是否在 SQL 前添加注释, 默认追加在 SQL 后
+	Prepend bool
+}
+
+// WithSQLComment 开启 SQL 注释, 按 sqlcommenter 规范在查询的 SQL 中添加标签注释, 用于在 MySQL 慢日志等处关联调用方.
+// 标签按 key 排序, 同名时 WithCommentTags 优先于 Context, Context 优先于 Tags; SQL 中已有注释(优化器提示 /*+ */ 除外)时不添加.
+// 开启语句缓存时, 仅注释只含静态标签 Tags 的 SQL 使用缓存; 含 Context 或 WithCommentTags 标签(每次请求不同)的 SQL 直接查询, 不预编译
+//
+// example:
+//
+//	New(db, WithSQLComment(SQLComment{Tags: map[string]string{"app": "api"}}))
+func WithSQLComment(c SQLComment) Option {
+	return func(o *options) {
+		o.comment = &c
+	}
+}
+
+type commentKey struct{}
+
+// WithCommentTags 返回添加了标签 tags 的 ctx, 用于 WithSQLComment 生成的注释, 可多次调用合并
+//
+// example:
+//
+//	ctx = WithCommentTags(ctx, map[string]string{"route": "/users/:id", "traceparent": traceparent})
+func WithCommentTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := make(map[string]string, len(tags))
+	if v, ok := ctx.Value(commentKey{}).(map[string]string); ok {
+		for k, val := range v {
+			merged[k] = val
+		}
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, commentKey{}, merged)
+}
+
+// apply 返回添加了注释的 query, dynamic 表示注释中是否有来自 ctx 的标签
+func (c *SQLComment) apply(ctx context.Context, query string) (_ string, dynamic bool) {
+	if hasComment(query) {
+		return query, false
+	}
+
+	tags := make(map[string]string, len(c.Tags))
+	for k, v := range c.Tags {
+		tags[k] = v
+	}
+	if nil != c.Context {
+		for k, v := range c.Context(ctx) {
+			tags[k] = v
+			dynamic = true
+		}
+	}
+	if v, ok := ctx.Value(commentKey{}).(map[string]string); ok {
+		for k, val := range v {
+			tags[k] = val
+			dynamic = true
+		}
+	}
+
+	comment := formatComment(tags)
+	if "" == comment {
+		return query, false
+	}
+	if c.Prepend {
+		return comment + " " + query, dynamic
+	}
+
+	return trimQuery(query) + " " + comment, dynamic
+}
+
+// formatComment 按 sqlcommenter 规范格式化 tags, 如 /*app='api',route='%2Fusers'*/, tags 为空时返回空字符串
+func formatComment(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		if "" != k {
+			keys = append(keys, k)
+		}
+	}
+	if 0 == len(keys) {
+		return ""
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("/*")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(commentEscape(k))
+		b.WriteString("='")
+		b.WriteString(strings.ReplaceAll(commentEscape(tags[k]), "'", `\'`))
+		b.WriteByte('\'')
+	}
+	b.WriteString("*/")
+
+	return b.String()
+}
+
+// commentEscape 同 JavaScript 的 encodeURIComponent, 保留字母、数字和 -_.!~*'()
+func commentEscape(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-_.!~*'()", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0xF])
+	}
+
+	return b.String()
+}
+
+// hasComment 判断 query 中是否有注释, 优化器提示 /*+ */ 不算作注释
+func hasComment(query string) bool {
+	for i := 0; ; {
+		j := strings.Index(query[i:], "/*")
+		if j < 0 {
+			return false
+		}
+		i += j + 2
+		if i >= len(query) || '+' != query[i] {
+			return true
+		}
+	}
+}
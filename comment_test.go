@@ -0,0 +1,121 @@
+package brows
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/beanscc/brows/browstest"
+)
+
+func TestFormatComment(t *testing.T) {
+	tests := []struct {
+		tags map[string]string
+		want string
+	}{
+		{nil, ""},
+		{map[string]string{"": "x"}, ""},
+		{map[string]string{"route": "/users/:id", "app": "api"}, `/*app='api',route='%2Fusers%2F%3Aid'*/`},
+		{map[string]string{"k y": "it's */"}, `/*k%20y='it\'s%20*%2F'*/`},
+	}
+	for _, tt := range tests {
+		if got := formatComment(tt.tags); got != tt.want {
+			t.Errorf("formatComment(%v) got:%s, want:%s", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestHasComment(t *testing.T) {
+	tests := map[string]bool{
+		`select 1`:                              false,
+		`select /*+ MAX_EXECUTION_TIME(1) */ 1`: false,
+		`select 1 /* a */`:                      true,
+		`select /*+ hint */ 1 /*x*/`:            true,
+		`select 1 /*`:                           true,
+	}
+	for query, want := range tests {
+		if got := hasComment(query); got != want {
+			t.Errorf("hasComment(%s) got:%v, want:%v", query, got, want)
+		}
+	}
+}
+
+func TestBrows_SQLComment(t *testing.T) {
+	id := func() *browstest.Result {
+		return browstest.NewResult("id").AddRow(int64(1))
+	}
+	db := browstest.NewDB(id(), id(), id())
+	defer db.Close()
+
+	type traceKey struct{}
+	b := New(db, WithSQLComment(SQLComment{
+		Tags: map[string]string{"app": "api", "route": "static"},
+		Context: func(ctx context.Context) map[string]string {
+			if v, ok := ctx.Value(traceKey{}).(string); ok {
+				return map[string]string{"traceparent": v}
+			}
+			return nil
+		},
+	}))
+
+	ctx := context.WithValue(context.Background(), traceKey{}, "00-abc-01")
+	ctx = WithCommentTags(ctx, map[string]string{"route": "/users"})
+	var out []struct {
+		ID int64 `db:"id"`
+	}
+	if err := b.QueryContext(ctx, `select id from user;`).Scan(&out); err != nil {
+		t.Fatalf("QueryContext err:%v", err)
+	}
+	if err := b.QueryContext(context.Background(), `select id from user /* keep */`).Scan(&out); err != nil {
+		t.Fatalf("QueryContext err:%v", err)
+	}
+
+	b = New(db, WithSQLComment(SQLComment{Tags: map[string]string{"app": "api"}, Prepend: true}))
+	if err := b.Query(`select id from user`).Scan(&out); err != nil {
+		t.Fatalf("Query err:%v", err)
+	}
+
+	want := []string{
+		`select id from user /*app='api',route='%2Fusers',traceparent='00-abc-01'*/`,
+		`select id from user /* keep */`,
+		`/*app='api'*/ select id from user`,
+	}
+	calls := db.Calls()
+	for i, v := range want {
+		if calls[i].Query != v {
+			t.Errorf("call[%d] got:%s, want:%s", i, calls[i].Query, v)
+		}
+	}
+}
+
+func TestBrows_SQLComment_StmtCache(t *testing.T) {
+	db := browstest.NewDB()
+	defer db.Close()
+	b := New(db, WithStmtCache(8), WithSQLComment(SQLComment{Tags: map[string]string{"app": "api"}}))
+
+	var out struct {
+		ID int64 `db:"id"`
+	}
+	for i := 0; i < 3; i++ {
+		// 每次请求不同的标签不预编译
+		db.Push(browstest.NewResult("id").AddRow(int64(1)), browstest.NewResult("id").AddRow(int64(1)))
+		ctx := WithCommentTags(context.Background(), map[string]string{"traceparent": strconv.Itoa(i)})
+		if err := b.QueryRowContext(ctx, `select id from user`).Scan(&out); err != nil {
+			t.Fatalf("QueryRowContext err:%v", err)
+		}
+		// 仅静态标签的 SQL 使用语句缓存
+		if err := b.QueryRow(`select id from user`).Scan(&out); err != nil {
+			t.Fatalf("QueryRow err:%v", err)
+		}
+	}
+
+	if got := countCalls(db, browstest.MethodPrepare); got != 1 {
+		t.Errorf("prepare got:%v, want:1", got)
+	}
+	if got := b.StmtCacheStats(); got.Size != 1 || got.Hits != 2 || got.Misses != 1 {
+		t.Errorf("StmtCacheStats got:%+v", got)
+	}
+	if calls := db.Calls(); calls[0].Query != `select id from user /*app='api',traceparent='0'*/` {
+		t.Errorf("call[0] got:%s", calls[0].Query)
+	}
+}
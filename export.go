@@ -0,0 +1,298 @@
+package brows
+
+import (
+	"bufio"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var ErrWriteModel = errors.New("brows: write model has no column in rows")
+
+// WriteOption WriteCSV, WriteJSONL 的配置项
+type WriteOption func(o *writeOptions)
+
+type writeOptions struct {
+	header     bool
+	model      any
+	null       string
+	timeFormat string
+	format     map[string]func(v any) any
+}
+
+// WriteHeader 写入列名作为 CSV 的第一行
+func WriteHeader() WriteOption {
+	return func(o *writeOptions) {
+		o.header = true
+	}
+}
+
+// WriteModel 按结构体 model 的列(见 Columns)顺序输出, 结果中不存在的列将被忽略, 结果中有而 model 中没有的列不输出.
+// 默认按查询结果的列顺序输出所有列
+func WriteModel(model any) WriteOption {
+	return func(o *writeOptions) {
+		o.model = model
+	}
+}
+
+// WriteNull 设置 NULL 在 CSV 中的文本, 默认空字符串. JSONL 中 NULL 始终为 null
+func WriteNull(null string) WriteOption {
+	return func(o *writeOptions) {
+		o.null = null
+	}
+}
+
+// WriteTimeFormat 设置 time.Time 的格式, 默认 time.RFC3339Nano
+func WriteTimeFormat(layout string) WriteOption {
+	return func(o *writeOptions) {
+		o.timeFormat = layout
+	}
+}
+
+// WriteFormat 自定义列 column 的值, fn 的参数为列的值(NULL 时为 nil), 返回值按默认规则输出
+//
+// example:
+//
+//	WriteFormat("price", func(v any) any { return fmt.Sprintf("%.2f", v) })
+func WriteFormat(column string, fn func(v any) any) WriteOption {
+	return func(o *writeOptions) {
+		if nil == o.format {
+			o.format = make(map[string]func(v any) any)
+		}
+		o.format[column] = fn
+	}
+}
+
+// WriteCSV 逐行将结果以 CSV 格式写入 w, 不缓存整个结果集, 完成后关闭 rows.
+//
+// 列的值按 ColumnTypes 的 ScanType 读取: 数字、布尔按 strconv 格式输出, time.Time 按 WriteTimeFormat 输出,
+// []byte 按文本输出, NULL 按 WriteNull 输出
+//
+// example:
+//
+//	err := b.Query(`select id,name,created_at from user`).WriteCSV(w, WriteHeader(), WriteTimeFormat(time.DateTime))
+func (rs *Rows) WriteCSV(w io.Writer, opts ...WriteOption) error {
+	cw := csv.NewWriter(w)
+	err := rs.write(opts, func(o *writeOptions, columns []string) error {
+		if o.header {
+			return cw.Write(columns)
+		}
+		return nil
+	}, func(o *writeOptions, columns []string, values []any) error {
+		record := make([]string, len(values))
+		for i, v := range values {
+			record[i] = o.text(v)
+		}
+		return cw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSONL 逐行将结果以 JSON Lines(NDJSON) 格式写入 w, 每行一个以列名为 key 的 JSON 对象, key 按列顺序排列.
+// 不缓存整个结果集, 完成后关闭 rows. 值的转换同 WriteCSV, 但数字、布尔和 NULL 输出为 JSON 对应的类型
+func (rs *Rows) WriteJSONL(w io.Writer, opts ...WriteOption) error {
+	bw := bufio.NewWriter(w)
+	var keys [][]byte
+	err := rs.write(opts, func(o *writeOptions, columns []string) error {
+		keys = make([][]byte, len(columns))
+		for i, v := range columns {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			keys[i] = b
+		}
+		return nil
+	}, func(o *writeOptions, columns []string, values []any) error {
+		bw.WriteByte('{')
+		for i, v := range values {
+			if i > 0 {
+				bw.WriteByte(',')
+			}
+			bw.Write(keys[i])
+			bw.WriteByte(':')
+
+			if t, ok := v.(time.Time); ok {
+				v = t.Format(o.timeFormat)
+			}
+			b, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("brows: column %s: %w", columns[i], err)
+			}
+			bw.Write(b)
+		}
+		bw.WriteByte('}')
+		return bw.WriteByte('\n')
+	})
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// write 逐行读取 rows, header 在读取前以输出的列名调用一次, row 以每行输出列的值调用
+func (rs *Rows) write(opts []WriteOption, header func(o *writeOptions, columns []string) error,
+	row func(o *writeOptions, columns []string, values []any) error) (err error) {
+	if rs.err != nil {
+		return rs.err
+	}
+	defer func() {
+		err = rs.finish(err)
+	}()
+	defer rs.rows.Close()
+
+	o := &writeOptions{timeFormat: time.RFC3339Nano}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	columns, err := rs.rows.Columns()
+	if err != nil {
+		return err
+	}
+	cts, err := rs.rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+
+	// 输出列在结果中的序号
+	outputs := make([]int, 0, len(columns))
+	if nil != o.model {
+		index := make(map[string]int, len(columns))
+		for i, v := range columns {
+			index[v] = i
+		}
+		for _, v := range Columns(o.model) {
+			if i, ok := index[v]; ok {
+				outputs = append(outputs, i)
+			}
+		}
+		if 0 == len(outputs) {
+			return ErrWriteModel
+		}
+	} else {
+		for i := range columns {
+			outputs = append(outputs, i)
+		}
+	}
+
+	names := make([]string, len(outputs))
+	for i, v := range outputs {
+		names[i] = columns[v]
+	}
+	if err := header(o, names); err != nil {
+		return err
+	}
+
+	dest := make([]any, len(columns))
+	for i, ct := range cts {
+		dest[i] = writeDest(ct)
+	}
+	values := make([]any, len(outputs))
+	for rs.rows.Next() {
+		if err := rs.rows.Scan(dest...); err != nil {
+			return err
+		}
+		for i, v := range outputs {
+			value, err := writeValue(dest[v])
+			if err != nil {
+				return fmt.Errorf("brows: column %s: %w", columns[v], err)
+			}
+			if fn, ok := o.format[columns[v]]; ok {
+				value = fn(value)
+			}
+			values[i] = value
+		}
+		if err := row(o, names, values); err != nil {
+			return err
+		}
+		rs.scanned++
+	}
+	if err := rs.rows.Err(); err != nil {
+		return err
+	}
+
+	return rs.rows.Close()
+}
+
+// writeDest 返回列 ct 的 Scan 目标. 不能接收 NULL 的类型使用二级指针
+func writeDest(ct *sql.ColumnType) any {
+	st := ct.ScanType()
+	if nil == st || reflect.Interface == st.Kind() {
+		return new(any)
+	}
+	if !nullSafe(st) {
+		st = reflect.PointerTo(st)
+	}
+
+	return reflect.New(st).Interface()
+}
+
+// writeValue 返回 Scan 目标 dest 中的值, NULL 返回 nil, []byte 转为 string
+func writeValue(dest any) (any, error) {
+	rv := reflect.ValueOf(dest).Elem()
+	for reflect.Pointer == rv.Kind() || reflect.Interface == rv.Kind() {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+
+	v := rv.Interface()
+	if valuer, ok := v.(driver.Valuer); ok {
+		var err error
+		if v, err = valuer.Value(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch val := v.(type) {
+	case []byte:
+		if nil == val {
+			return nil, nil
+		}
+		return string(val), nil
+	case sql.RawBytes:
+		if nil == val {
+			return nil, nil
+		}
+		return string(val), nil
+	default:
+		return v, nil
+	}
+}
+
+// text 返回 v 在 CSV 中的文本
+func (o *writeOptions) text(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return o.null
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case time.Time:
+		return val.Format(o.timeFormat)
+	case bool:
+		return strconv.FormatBool(val)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,109 @@
+package brows
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/beanscc/brows/browstest"
+)
+
+func exportResult() *browstest.Result {
+	at := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	return browstest.NewResult("id", "name", "score", "ok", "created_at").
+		AddRow(int64(1), []byte("a,b"), 1.5, true, at).
+		AddRow(int64(2), nil, nil, false, at)
+}
+
+func TestRows_WriteCSV(t *testing.T) {
+	db := browstest.NewDB(exportResult(), exportResult())
+	defer db.Close()
+	b := New(db)
+
+	var buf bytes.Buffer
+	err := b.Query(`select * from user`).WriteCSV(&buf, WriteHeader(), WriteNull(`\N`), WriteTimeFormat(time.DateOnly))
+	if err != nil {
+		t.Fatalf("WriteCSV err:%v", err)
+	}
+	want := "id,name,score,ok,created_at\n" +
+		"1,\"a,b\",1.5,true,2023-01-02\n" +
+		"2,\\N,\\N,false,2023-01-02\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteCSV got:\n%s\nwant:\n%s", got, want)
+	}
+
+	type Model struct {
+		Name string `db:"name"`
+		None string `db:"none"`
+		ID   int64  `db:"id"`
+	}
+	buf.Reset()
+	err = b.Query(`select * from user`).WriteCSV(&buf, WriteModel(Model{}), WriteFormat("id", func(v any) any {
+		return v.(int64) * 10
+	}))
+	if err != nil {
+		t.Fatalf("WriteCSV with model err:%v", err)
+	}
+	if got, want := buf.String(), "\"a,b\",10\n,20\n"; got != want {
+		t.Errorf("WriteCSV with model got:%q, want:%q", got, want)
+	}
+}
+
+func TestRows_WriteJSONL(t *testing.T) {
+	db := browstest.NewDB(exportResult(), browstest.NewResult("id").AddRow(int64(1)), browstest.NewErrResult(sql.ErrConnDone))
+	defer db.Close()
+	b := New(db)
+
+	var buf bytes.Buffer
+	if err := b.Query(`select * from user`).WriteJSONL(&buf); err != nil {
+		t.Fatalf("WriteJSONL err:%v", err)
+	}
+	want := `{"id":1,"name":"a,b","score":1.5,"ok":true,"created_at":"2023-01-02T03:04:05Z"}` + "\n" +
+		`{"id":2,"name":null,"score":null,"ok":false,"created_at":"2023-01-02T03:04:05Z"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteJSONL got:\n%s\nwant:\n%s", got, want)
+	}
+
+	err := b.Query(`select id from user`).WriteJSONL(&buf, WriteModel(struct {
+		Name string `db:"name"`
+	}{}))
+	if !errors.Is(err, ErrWriteModel) {
+		t.Errorf("WriteJSONL want ErrWriteModel, got:%v", err)
+	}
+
+	if err := b.Query(`select id from user`).WriteJSONL(&buf); !errors.Is(err, sql.ErrConnDone) {
+		t.Errorf("WriteJSONL want sql.ErrConnDone, got:%v", err)
+	}
+}
+
+func TestWriteValue(t *testing.T) {
+	var (
+		null  sql.NullString
+		valid = sql.NullInt64{Int64: 3, Valid: true}
+		raw   = sql.RawBytes("x")
+		ptr   *int64
+		iface any = []byte(nil)
+	)
+	tests := []struct {
+		dest any
+		want any
+	}{
+		{&null, nil},
+		{&valid, int64(3)},
+		{&raw, "x"},
+		{&ptr, nil},
+		{&iface, nil},
+	}
+	for _, tt := range tests {
+		got, err := writeValue(tt.dest)
+		if err != nil || got != tt.want {
+			t.Errorf("writeValue(%T) got:%v, err:%v, want:%v", tt.dest, got, err, tt.want)
+		}
+	}
+
+	if got := (&writeOptions{}).text(float32(0.1)); got != "0.1" {
+		t.Errorf("text(float32) got:%v", got)
+	}
+}
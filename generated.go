@@ -0,0 +1,34 @@
+package brows
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// ColumnScanner 将 columns 对应的 Scan 目标写入 dest(与 columns 等长), 一般由 cmd/brows-gen 为结构体生成.
+//
+// Scan/ScanSlice 发现目标结构体(指针)实现了 ColumnScanner 时, 将使用其写入的目标，而不再通过反射映射字段.
+// 不需要的列应写入 Discard. ScanSlice 读取每一行时复用 dest
+//
+// 以下配置依赖反射映射字段，开启时不使用 ColumnScanner: 非 MatchExact 的匹配方式, WithNullZero, WithColumnTypeCheck
+type ColumnScanner interface {
+	ScanBrows(columns []string, dest []any)
+}
+
+// Discard 作为 Scan 目标时, 丢弃列值
+var Discard sql.Scanner = _ignoreScan
+
+var _columnScannerType = reflect.TypeOf((*ColumnScanner)(nil)).Elem()
+
+// columnScanner 返回 rv(结构体指针) 实现的 ColumnScanner. o 中的配置依赖反射时返回 false
+func columnScanner(rv reflect.Value, o *options) (ColumnScanner, bool) {
+	if MatchExact != o.match || o.nullZero || o.checkColumnTypes {
+		return nil, false
+	}
+
+	if !rv.Type().Implements(_columnScannerType) {
+		return nil, false
+	}
+
+	return rv.Interface().(ColumnScanner), true
+}
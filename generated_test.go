@@ -0,0 +1,64 @@
+package brows
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/beanscc/brows/browstest"
+)
+
+type generatedUser struct {
+	ID    int64  `db:"id"`
+	Name  string `db:"name"`
+	calls int
+}
+
+func (v *generatedUser) ScanBrows(columns []string, dest []any) {
+	v.calls++
+	for i, column := range columns {
+		switch column {
+		case "id":
+			dest[i] = &v.ID
+		case "name":
+			dest[i] = &v.Name
+		default:
+			dest[i] = Discard
+		}
+	}
+}
+
+func TestColumnScanner(t *testing.T) {
+	test := []struct {
+		name string
+		opts []Option
+		want bool
+	}{
+		{name: "default", want: true},
+		{name: "match mode", opts: []Option{WithMatchMode(MatchCaseInsensitive)}, want: false},
+		{name: "null zero", opts: []Option{WithNullZero()}, want: false},
+		{name: "column type check", opts: []Option{WithColumnTypeCheck()}, want: false},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := columnScanner(reflect.ValueOf(&generatedUser{}), newOptions(tt.opts...))
+			if ok != tt.want {
+				t.Errorf("columnScanner got:%v, want:%v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrows_Fake_ColumnScanner(t *testing.T) {
+	db := browstest.NewDB(browstest.NewResult("id", "name", "other").AddRow(int64(1), "a", "x").AddRow(int64(2), "b", "y"))
+	defer db.Close()
+
+	var out []*generatedUser
+	if err := New(db).Query(`select id,name,other from user`).Scan(&out); err != nil {
+		t.Fatalf("Query err:%v", err)
+	}
+
+	if len(out) != 2 || out[1].ID != 2 || out[1].Name != "b" || out[1].calls != 1 {
+		t.Errorf("Query got unexpected out:%#v", out)
+	}
+}
@@ -1,5 +1,5 @@
 module github.com/beanscc/brows
 
-go 1.20
+go 1.21
 
 require github.com/go-sql-driver/mysql v1.7.1
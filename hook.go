@@ -0,0 +1,99 @@
+package brows
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	ErrUnknownColumn = errors.New("brows: unknown column")
+	ErrArgsSource    = errors.New("brows: ArgsOf source must be a struct or a non-nil pointer to a struct")
+)
+
+// AfterScanner 实现了 AfterScan 的结构体在 Scan 每一行记录后调用 AfterScan, 如解码标志位、计算派生字段.
+// 返回错误时终止 Scan 并返回该错误
+type AfterScanner interface {
+	AfterScan() error
+}
+
+// Validator 实现了 Validate 的结构体在 ArgsOf 取值前调用 Validate, 返回错误时 ArgsOf 返回该错误
+type Validator interface {
+	Validate() error
+}
+
+// afterScan 调用 rv(需为指针)的 AfterScan
+func afterScan(rv reflect.Value) error {
+	if s, ok := rv.Interface().(AfterScanner); ok {
+		return s.AfterScan()
+	}
+
+	return nil
+}
+
+// ArgsOf 按 columns 的顺序返回结构体 v 中对应字段(按 tag 匹配, 同 Scan)的值, 用于 insert, update 等语句的参数.
+// columns 为空时返回所有列, 顺序同 Columns. v 可以是 struct 或 *struct, 实现了 Validator 时先调用 Validate.
+// 字段所在的嵌套结构体指针为 nil 时值为 nil
+//
+// example:
+//
+//	args, err := ArgsOf(user, "name", "age")
+//	if err != nil {
+//		return err
+//	}
+//	_, err = db.ExecContext(ctx, `insert into user (name, age) values (?, ?)`, args...)
+func ArgsOf(v any, columns ...string) ([]any, error) {
+	rv := reflect.ValueOf(v)
+	if reflect.Pointer == rv.Kind() {
+		if rv.IsNil() {
+			return nil, ErrArgsSource
+		}
+		rv = rv.Elem()
+	}
+	if reflect.Struct != rv.Kind() {
+		return nil, fmt.Errorf("%w, got %T", ErrArgsSource, v)
+	}
+
+	// 值接收者和指针接收者的 Validate 均调用
+	pv := rv
+	if !rv.CanAddr() {
+		pv = reflect.New(rv.Type()).Elem()
+		pv.Set(rv)
+	}
+	if validator, ok := pv.Addr().Interface().(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if 0 == len(columns) {
+		columns = Columns(rv.Type())
+	}
+
+	m := typeMapping(rv.Type())
+	out := make([]any, 0, len(columns))
+	for _, column := range columns {
+		f, ok := m[column]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s not found in %s", ErrUnknownColumn, column, rv.Type())
+		}
+		out = append(out, fieldValue(rv, f.index))
+	}
+
+	return out, nil
+}
+
+// fieldValue 返回 rv 中 index 对应字段的值, 路径上有 nil 指针时返回 nil
+func fieldValue(rv reflect.Value, index []int) any {
+	for i, v := range index {
+		if i > 0 && reflect.Pointer == rv.Kind() {
+			if rv.IsNil() {
+				return nil
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(v)
+	}
+
+	return rv.Interface()
+}
@@ -0,0 +1,105 @@
+package brows
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/beanscc/brows/browstest"
+)
+
+var errBadFlags = errors.New("bad flags")
+
+type hookUser struct {
+	ID    int64  `db:"id"`
+	Flags string `db:"flags"`
+	Admin bool   `db:"-"`
+}
+
+func (u *hookUser) AfterScan() error {
+	if strings.Contains(u.Flags, "?") {
+		return errBadFlags
+	}
+	u.Admin = strings.Contains(u.Flags, "a")
+	return nil
+}
+
+func TestAfterScan(t *testing.T) {
+	users := func(flags ...string) *browstest.Result {
+		r := browstest.NewResult("id", "flags")
+		for i, v := range flags {
+			r.AddRow(int64(i+1), v)
+		}
+		return r
+	}
+	db := browstest.NewDB(users("ab", "b"), users("a"), users("b", "?"), users("?"), users("a"))
+	defer db.Close()
+	b := New(db)
+
+	var list []*hookUser
+	if err := b.Query(`select id,flags from user`).Scan(&list); err != nil || len(list) != 2 || !list[0].Admin || list[1].Admin {
+		t.Errorf("Scan got:%v, err:%v", list, err)
+	}
+
+	var one hookUser
+	if err := b.QueryRow(`select id,flags from user`).Scan(&one); err != nil || !one.Admin {
+		t.Errorf("Row.Scan got:%v, err:%v", one, err)
+	}
+
+	var values []hookUser
+	if err := b.Query(`select id,flags from user`).Scan(&values); !errors.Is(err, errBadFlags) {
+		t.Errorf("Scan want errBadFlags, got:%v", err)
+	}
+
+	var positional hookUser
+	if err := b.QueryRow(`select id,flags from user`).ScanPositional(&positional); !errors.Is(err, errBadFlags) {
+		t.Errorf("ScanPositional want errBadFlags, got:%v", err)
+	}
+	if err := b.Query(`select id,flags from user`).ScanPositional(&values); err != nil || len(values) != 1 || !values[0].Admin {
+		t.Errorf("ScanSlicePositional got:%v, err:%v", values, err)
+	}
+}
+
+type argsUser struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+	*ArgsProfile
+}
+
+type ArgsProfile struct {
+	Email string `db:"email"`
+}
+
+func (u argsUser) Validate() error {
+	if "" == u.Name {
+		return errors.New("name required")
+	}
+	return nil
+}
+
+func TestArgsOf(t *testing.T) {
+	u := argsUser{ID: 1, Name: "a"}
+	args, err := ArgsOf(u)
+	if err != nil || len(args) != 3 || args[0] != int64(1) || args[1] != "a" || args[2] != nil {
+		t.Errorf("ArgsOf got:%v, err:%v", args, err)
+	}
+
+	u.ArgsProfile = &ArgsProfile{Email: "a@b.c"}
+	args, err = ArgsOf(&u, "email", "id")
+	if err != nil || len(args) != 2 || args[0] != "a@b.c" || args[1] != int64(1) {
+		t.Errorf("ArgsOf got:%v, err:%v", args, err)
+	}
+
+	if _, err := ArgsOf(u, "age"); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("ArgsOf want ErrUnknownColumn, got:%v", err)
+	}
+	if _, err := ArgsOf(argsUser{}); err == nil || err.Error() != "name required" {
+		t.Errorf("ArgsOf want Validate error, got:%v", err)
+	}
+	if _, err := ArgsOf(1); !errors.Is(err, ErrArgsSource) {
+		t.Errorf("ArgsOf want ErrArgsSource, got:%v", err)
+	}
+	if _, err := ArgsOf((*argsUser)(nil)); !errors.Is(err, ErrArgsSource) {
+		t.Errorf("ArgsOf nil pointer want ErrArgsSource, got:%v", err)
+	}
+}
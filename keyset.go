@@ -0,0 +1,269 @@
+package brows
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrKeysetCursor = errors.New("brows: invalid keyset cursor")
+	ErrKeysetKey    = errors.New("brows: keyset key column not found in destination")
+)
+
+// KeysetIter 键集(游标)分页迭代器, 由 Brows.Keyset 创建, 不可并发使用
+type KeysetIter struct {
+	brows *Brows
+	ctx   context.Context
+	query string
+	args  []any
+	key   string
+	size  int
+
+	// last 上一页最后一行的 key, nil 表示从头开始
+	last any
+	// more 是否可能还有下一页
+	more bool
+	err  error
+}
+
+// Keyset 返回以 keyColumn 升序分页的迭代器, 每页 pageSize 条.
+//
+// 每页通过 select * from (baseQuery) as _brows_keyset where keyColumn > ? order by keyColumn limit pageSize 查询,
+// 因此 keyColumn 必须是 baseQuery 结果中唯一且不为 NULL 的列, baseQuery 不需要 order by 和 limit.
+// args 为 baseQuery 的参数
+//
+// example:
+//
+//	it := b.Keyset(ctx, `select id,name from user where age > ?`, "id", 1000, 10)
+//	if err := it.Seek(cursor); err != nil { // 可选, 从客户端传回的游标继续
+//		return err
+//	}
+//	var users []User
+//	for it.Next(&users) {
+//		// users 为当前页的记录, it.Cursor() 为下一页的游标
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+func (b *Brows) Keyset(ctx context.Context, baseQuery string, keyColumn string, pageSize int, args ...any) *KeysetIter {
+	it := &KeysetIter{
+		brows: b,
+		ctx:   ctx,
+		query: baseQuery,
+		args:  args,
+		key:   keyColumn,
+		size:  pageSize,
+		more:  true,
+	}
+	if pageSize <= 0 {
+		it.err = ErrPageSize
+	}
+
+	return it
+}
+
+// Seek 从 cursor 之后开始迭代, cursor 为 Cursor 返回的游标, 为空时从头开始
+func (it *KeysetIter) Seek(cursor string) error {
+	last, err := decodeCursor(cursor)
+	if err != nil {
+		return err
+	}
+
+	it.last, it.more = last, true
+	return nil
+}
+
+// Next 查询下一页记录到 dest, dest 要求同 Rows.Scan, 查询前会清空 dest.
+// 没有更多记录或出错时返回 false, 错误通过 Err 获取
+func (it *KeysetIter) Next(dest any) bool {
+	if it.err != nil || !it.more {
+		return false
+	}
+
+	rv := reflect.ValueOf(dest)
+	if reflect.Pointer != rv.Kind() || rv.IsNil() || reflect.Slice != rv.Elem().Kind() {
+		it.err = ErrScanSliceDestination
+		return false
+	}
+	slice := rv.Elem()
+	slice.Set(reflect.MakeSlice(slice.Type(), 0, it.size))
+
+	query, args := it.next()
+	if it.err = it.brows.QueryContext(it.ctx, query, args...).Scan(dest); it.err != nil {
+		return false
+	}
+
+	n := slice.Len()
+	if 0 == n {
+		it.more = false
+		return false
+	}
+
+	last, err := keyOf(slice.Index(n-1), it.key, it.brows.opts)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.last, it.more = last, n >= it.size
+
+	return true
+}
+
+// Cursor 返回当前页之后的游标, 用于 Seek 继续迭代. 没有更多记录时返回空字符串
+func (it *KeysetIter) Cursor() string {
+	if !it.more || nil == it.last {
+		return ""
+	}
+
+	cursor, err := encodeCursor(it.last)
+	if err != nil {
+		it.err = err
+		return ""
+	}
+
+	return cursor
+}
+
+// Err 返回迭代过程中的错误
+func (it *KeysetIter) Err() error {
+	return it.err
+}
+
+// next 返回下一页的查询和参数
+func (it *KeysetIter) next() (string, []any) {
+	query := "select * from (" + trimQuery(it.query) + ") as _brows_keyset"
+	args := it.args
+	if nil != it.last {
+		placeholder := "?"
+		if PlaceholderDollar == it.brows.opts.placeholder {
+			placeholder = "$" + strconv.Itoa(len(args)+1)
+		}
+		query += " where " + it.key + " > " + placeholder
+		args = append(args[:len(args):len(args)], it.last)
+	}
+	query += " order by " + it.key + " limit " + strconv.Itoa(it.size)
+
+	return query, args
+}
+
+// keyOf 返回切片元素 rv 中 key 列对应字段的值
+func keyOf(rv reflect.Value, key string, o *options) (any, error) {
+	if reflect.Pointer == rv.Kind() {
+		rv = rv.Elem()
+	}
+
+	f, ok := o.match.matcher(typeMapping(rv.Type()))(key)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeysetKey, key)
+	}
+
+	for _, i := range f.index {
+		if reflect.Pointer == rv.Kind() {
+			if rv.IsNil() {
+				return nil, fmt.Errorf("%w: %s is nil", ErrKeysetKey, key)
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(i)
+	}
+	if reflect.Pointer == rv.Kind() && rv.IsNil() {
+		return nil, fmt.Errorf("%w: %s is nil", ErrKeysetKey, key)
+	}
+
+	v := rv.Interface()
+	if valuer, ok := v.(driver.Valuer); ok {
+		return valuer.Value()
+	}
+
+	return v, nil
+}
+
+// cursorKey 游标中的 key 及其类型, 避免 json 丢失类型和精度
+type cursorKey struct {
+	// Type 类型: int, uint, float, string, bytes, bool, time
+	Type  string `json:"t"`
+	Value string `json:"v"`
+}
+
+// encodeCursor 编码游标为 base64(json). 支持整数、浮点数、字符串、[]byte、bool 和 time.Time 类型的 key
+func encodeCursor(v any) (string, error) {
+	var key cursorKey
+	switch val := v.(type) {
+	case time.Time:
+		key = cursorKey{Type: "time", Value: val.Format(time.RFC3339Nano)}
+	case []byte:
+		key = cursorKey{Type: "bytes", Value: base64.RawURLEncoding.EncodeToString(val)}
+	default:
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			key = cursorKey{Type: "int", Value: strconv.FormatInt(rv.Int(), 10)}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			key = cursorKey{Type: "uint", Value: strconv.FormatUint(rv.Uint(), 10)}
+		case reflect.Float32, reflect.Float64:
+			key = cursorKey{Type: "float", Value: strconv.FormatFloat(rv.Float(), 'g', -1, 64)}
+		case reflect.String:
+			key = cursorKey{Type: "string", Value: rv.String()}
+		case reflect.Bool:
+			key = cursorKey{Type: "bool", Value: strconv.FormatBool(rv.Bool())}
+		default:
+			return "", fmt.Errorf("%w: unsupported key type %T", ErrKeysetCursor, v)
+		}
+	}
+
+	b, err := json.Marshal(key)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrKeysetCursor, err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeCursor 解码 encodeCursor 生成的游标, key 解码为 int64, uint64, float64, string, []byte, bool 或 time.Time
+func decodeCursor(cursor string) (any, error) {
+	if "" == cursor {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeysetCursor, err)
+	}
+
+	var key cursorKey
+	if err := json.Unmarshal(b, &key); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeysetCursor, err)
+	}
+
+	var v any
+	switch key.Type {
+	case "int":
+		v, err = strconv.ParseInt(key.Value, 10, 64)
+	case "uint":
+		v, err = strconv.ParseUint(key.Value, 10, 64)
+	case "float":
+		v, err = strconv.ParseFloat(key.Value, 64)
+	case "string":
+		v = key.Value
+	case "bytes":
+		v, err = base64.RawURLEncoding.DecodeString(key.Value)
+	case "bool":
+		v, err = strconv.ParseBool(key.Value)
+	case "time":
+		v, err = time.Parse(time.RFC3339Nano, key.Value)
+	default:
+		return nil, fmt.Errorf("%w: unsupported key %s", ErrKeysetCursor, b)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeysetCursor, err)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,138 @@
+package brows
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/beanscc/brows/browstest"
+)
+
+func TestBrows_Keyset(t *testing.T) {
+	type User struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+
+	db := browstest.NewDB(
+		browstest.NewResult("id", "name").AddRow(int64(1), "a").AddRow(int64(2), "b"),
+		browstest.NewResult("id", "name").AddRow(int64(3), "c"),
+	)
+	defer db.Close()
+	b := New(db, WithPlaceholder(PlaceholderDollar))
+
+	it := b.Keyset(context.Background(), `select id,name from user where age > $1;`, "id", 2, 10)
+	var (
+		users   []*User
+		ids     []int64
+		cursors []string
+	)
+	for it.Next(&users) {
+		for _, v := range users {
+			ids = append(ids, v.ID)
+		}
+		cursors = append(cursors, it.Cursor())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Keyset err:%v", err)
+	}
+	if len(ids) != 3 || ids[2] != 3 || len(cursors) != 2 || "" == cursors[0] || "" != cursors[1] {
+		t.Errorf("Keyset got ids:%v, cursors:%v", ids, cursors)
+	}
+
+	calls := db.Calls()
+	want := []string{
+		`select * from (select id,name from user where age > $1) as _brows_keyset order by id limit 2`,
+		`select * from (select id,name from user where age > $1) as _brows_keyset where id > $2 order by id limit 2`,
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("Keyset got calls:%v", calls)
+	}
+	for i, v := range want {
+		if calls[i].Query != v {
+			t.Errorf("Keyset call[%d] got:%v, want:%v", i, calls[i].Query, v)
+		}
+	}
+	if args := calls[1].Args; len(args) != 2 || args[1] != int64(2) {
+		t.Errorf("Keyset got args:%v", args)
+	}
+
+	// 从游标继续
+	db.Reset()
+	db.Push(browstest.NewResult("id", "name"))
+	it = b.Keyset(context.Background(), `select id,name from user where age > $1`, "id", 2, 10)
+	if err := it.Seek(cursors[0]); err != nil {
+		t.Fatalf("Seek err:%v", err)
+	}
+	if it.Next(&users) || it.Err() != nil || len(users) != 0 {
+		t.Errorf("Keyset after Seek got users:%v, err:%v", users, it.Err())
+	}
+	if args := db.Calls()[0].Args; len(args) != 2 || args[1] != int64(2) {
+		t.Errorf("Keyset after Seek got args:%v", args)
+	}
+}
+
+func TestBrows_Keyset_Error(t *testing.T) {
+	db := browstest.NewDB(browstest.NewResult("id").AddRow(int64(1)))
+	defer db.Close()
+	b := New(db)
+
+	var out []struct {
+		Name string `db:"name"`
+	}
+	it := b.Keyset(context.Background(), `select id from user`, "id", 10)
+	if it.Next(&out) || !errors.Is(it.Err(), ErrKeysetKey) {
+		t.Errorf("Keyset want ErrKeysetKey, got:%v", it.Err())
+	}
+
+	if it := b.Keyset(context.Background(), `select id from user`, "id", 0); it.Next(&out) || !errors.Is(it.Err(), ErrPageSize) {
+		t.Errorf("Keyset want ErrPageSize, got:%v", it.Err())
+	}
+
+	for _, v := range []string{"!", "e30", "bnVsbA"} { // 非 base64, {}, null
+		if err := b.Keyset(context.Background(), `select id from user`, "id", 10).Seek(v); !errors.Is(err, ErrKeysetCursor) {
+			t.Errorf("Seek(%q) want ErrKeysetCursor, got:%v", v, err)
+		}
+	}
+}
+
+func TestCursor(t *testing.T) {
+	type id int32
+	tests := []struct {
+		in   any
+		want any
+	}{
+		{int64(1) << 60, int64(1) << 60},
+		{uint8(7), uint64(7)},
+		{id(-3), int64(-3)},
+		{uint64(math.MaxUint64), uint64(math.MaxUint64)},
+		{1.5, 1.5},
+		{"abc", "abc"},
+		{[]byte("a\x00b"), []byte("a\x00b")},
+		{true, true},
+		{time.Date(2024, 2, 3, 4, 5, 6, 789, time.UTC), time.Date(2024, 2, 3, 4, 5, 6, 789, time.UTC)},
+	}
+	for _, tt := range tests {
+		cursor, err := encodeCursor(tt.in)
+		if err != nil {
+			t.Fatalf("encodeCursor(%v) err:%v", tt.in, err)
+		}
+		got, err := decodeCursor(cursor)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("decodeCursor(encodeCursor(%v)) got:%v(%T), err:%v, want:%v", tt.in, got, got, err, tt.want)
+		}
+	}
+
+	if _, err := encodeCursor(struct{}{}); !errors.Is(err, ErrKeysetCursor) {
+		t.Errorf("encodeCursor(struct{}) want ErrKeysetCursor, got:%v", err)
+	}
+	for _, cursor := range []string{"!", base64.RawURLEncoding.EncodeToString([]byte(`1`)), base64.RawURLEncoding.EncodeToString([]byte(`{"t":"int","v":"x"}`))} {
+		if _, err := decodeCursor(cursor); !errors.Is(err, ErrKeysetCursor) {
+			t.Errorf("decodeCursor(%s) want ErrKeysetCursor, got:%v", cursor, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package brows
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var _valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// _leafTypes 注册的叶子类型: reflect.Type -> leafScanFunc
+var _leafTypes sync.Map
+
+// leafScanFunc 将列值 src 转换后写入 dest, dest 为指向叶子类型值的指针
+type leafScanFunc func(dest reflect.Value, src any) error
+
+// RegisterLeafType 注册叶子类型 T. 叶子类型的结构体字段将作为整体接收列值, 而不是展开遍历其内部字段.
+//
+// time.Time 以及实现了 sql.Scanner 或 driver.Valuer (值或指针接收者)的类型默认即为叶子类型,
+// 其他类型(如未实现 sql.Scanner 的第三方 decimal 结构体)可通过该方法注册.
+// database/sql 无法直接写入这类结构体, Scan 时由 scan 将列值 src 转换后写入 dest;
+// src 为 driver.Value (int64, float64, bool, []byte, string, time.Time), 不会为 nil:
+// 列值为 NULL 时, *T 类型的字段置为 nil, T 类型的字段返回错误(nullzero 字段赋零值).
+// 需在首次 Scan 前调用, 如 init 中
+//
+// example:
+//
+//	brows.RegisterLeafType(func(dest *Decimal, src any) error {
+//		return dest.UnmarshalText([]byte(fmt.Sprint(src)))
+//	})
+func RegisterLeafType[T any](scan func(dest *T, src any) error) {
+	rt := reflect.TypeOf((*T)(nil)).Elem()
+	_leafTypes.Store(rt, leafScanFunc(func(dest reflect.Value, src any) error {
+		return scan(dest.Interface().(*T), src)
+	}))
+}
+
+// leaf 判断结构体类型 rt 是否为叶子类型
+func leaf(rt reflect.Type) bool {
+	if "time.Time" == rt.String() {
+		return true
+	}
+
+	if _, ok := _leafTypes.Load(rt); ok {
+		return true
+	}
+
+	pt := reflect.PointerTo(rt)
+	return rt.Implements(_scannerType) || pt.Implements(_scannerType) ||
+		rt.Implements(_valuerType) || pt.Implements(_valuerType)
+}
+
+// registeredLeaf 判断 rt 是否通过 RegisterLeafType 注册
+func registeredLeaf(rt reflect.Type) bool {
+	return nil != leafScanner(rt)
+}
+
+// leafScanner 返回 rt 或 *rt 类型注册的转换函数, 未注册时返回 nil
+func leafScanner(rt reflect.Type) leafScanFunc {
+	if reflect.Pointer == rt.Kind() {
+		rt = rt.Elem()
+	}
+	if reflect.Struct != rt.Kind() {
+		return nil
+	}
+
+	fn, ok := _leafTypes.Load(rt)
+	// 实现了 sql.Scanner 的类型由 database/sql 直接写入
+	if !ok || rt.Implements(_scannerType) || reflect.PointerTo(rt).Implements(_scannerType) {
+		return nil
+	}
+	return fn.(leafScanFunc)
+}
+
+// scanTarget 返回可寻址的字段 fv 作为 Rows.Scan 目标的值, 注册的叶子类型通过 leafHolder 转换
+func scanTarget(fv reflect.Value) any {
+	if fn := leafScanner(fv.Type()); nil != fn {
+		return &leafHolder{dest: fv, scan: fn}
+	}
+
+	return fv.Addr().Interface()
+}
+
+// leafHolder 接收注册的叶子类型(T 或 *T)的列值
+type leafHolder struct {
+	dest reflect.Value
+	scan leafScanFunc
+}
+
+func (h *leafHolder) Scan(src any) error {
+	dest := h.dest
+	if reflect.Pointer == dest.Kind() {
+		if nil == src {
+			dest.SetZero()
+			return nil
+		}
+		if dest.IsNil() {
+			dest.Set(reflect.New(dest.Type().Elem()))
+		}
+		dest = dest.Elem()
+	} else if nil == src {
+		return fmt.Errorf("converting NULL to %s is unsupported", dest.Type())
+	}
+
+	return h.scan(dest.Addr(), src)
+}
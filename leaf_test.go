@@ -0,0 +1,156 @@
+package brows
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beanscc/brows/browstest"
+)
+
+// money 指针接收者实现 sql.Scanner
+type money struct {
+	Cents int64 `db:"cents"`
+}
+
+func (m *money) Scan(src any) error { return nil }
+
+// amount 仅实现 driver.Valuer
+type amount struct {
+	Raw string `db:"raw"`
+}
+
+func (a amount) Value() (driver.Value, error) { return a.Raw, nil }
+
+// decimal 未实现 sql.Scanner, 通过 RegisterLeafType 注册
+type decimal struct {
+	Int  int64 `db:"int"`
+	Frac int64 `db:"frac"`
+}
+
+func init() {
+	RegisterLeafType(func(dest *decimal, src any) error {
+		var s string
+		switch v := src.(type) {
+		case []byte:
+			s = string(v)
+		case string:
+			s = v
+		default:
+			return fmt.Errorf("decimal: unsupported %T", src)
+		}
+		_, err := fmt.Sscanf(s, "%d.%d", &dest.Int, &dest.Frac)
+		return err
+	})
+}
+
+// unregistered 未实现 sql.Scanner, 也未注册
+type unregistered struct {
+	Int int64 `db:"int"`
+}
+
+func TestLeaf(t *testing.T) {
+
+	test := []struct {
+		rt   reflect.Type
+		want bool
+	}{
+		{rt: reflect.TypeOf(time.Time{}), want: true},
+		{rt: reflect.TypeOf(sql.NullString{}), want: true},
+		{rt: reflect.TypeOf(money{}), want: true},
+		{rt: reflect.TypeOf(amount{}), want: true},
+		{rt: reflect.TypeOf(decimal{}), want: true},
+		{rt: reflect.TypeOf(unregistered{}), want: false},
+	}
+
+	for _, tt := range test {
+		if got := leaf(tt.rt); got != tt.want {
+			t.Errorf("leaf(%s) got:%v, want:%v", tt.rt, got, tt.want)
+		}
+	}
+}
+
+func TestMapping_Leaf(t *testing.T) {
+	type T struct {
+		Note    sql.NullString `db:"note"`
+		Price   money          `db:"price"`
+		Cost    *money         `db:"cost"`
+		Amount  amount         `db:"amount"`
+		Decimal decimal        `db:"decimal"`
+		// 内嵌的叶子类型按 tag 映射
+		sql.NullInt64 `db:"count"`
+	}
+
+	got := mapping(reflect.TypeOf(T{}), _tagLabel)
+	want := map[string][]int{
+		"note":    {0},
+		"price":   {1},
+		"cost":    {2},
+		"amount":  {3},
+		"decimal": {4},
+		"count":   {5},
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("mapping got:%v", got)
+	}
+	for k, index := range want {
+		if f, ok := got[k]; !ok || !reflect.DeepEqual(f.index, index) {
+			t.Errorf("mapping tag:%s got:%v, want index:%v", k, f, index)
+		}
+	}
+
+	// 仅实现 driver.Valuer 的类型不能作为 Scan 目标, 注册的类型通过转换函数写入
+	err := Validate(reflect.TypeOf(T{}))
+	if !errors.Is(err, ErrUnsupportedField) || !strings.Contains(err.Error(), "T.Amount") || strings.Contains(err.Error(), "\n") {
+		t.Errorf("Validate want ErrUnsupportedField for T.Amount, got:%v", err)
+	}
+}
+
+func TestScan_RegisteredLeaf(t *testing.T) {
+	type T struct {
+		ID    int64    `db:"id"`
+		Price decimal  `db:"price"`
+		Cost  *decimal `db:"cost"`
+		Fee   decimal  `db:"fee,nullzero"`
+	}
+	db := browstest.NewDB(
+		browstest.NewResult("id", "price", "cost", "fee").
+			AddRow(int64(1), []byte("1.25"), "2.50", nil).
+			AddRow(int64(2), "3.75", nil, "0.01"),
+		browstest.NewResult("price", "cost").AddRow("1.25", nil).AddRow([]byte("3.75"), "2.50"),
+		browstest.NewResult("id", "price").AddRow(int64(1), nil),
+	)
+	defer db.Close()
+	b := New(db)
+
+	want := []T{
+		{ID: 1, Price: decimal{1, 25}, Cost: &decimal{2, 50}},
+		{ID: 2, Price: decimal{3, 75}, Fee: decimal{0, 1}},
+	}
+	var got []T
+	if err := b.Query(`select id,price,cost,fee from t`).Scan(&got); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan got:%+v, err:%v", got, err)
+	}
+
+	type P struct {
+		Price decimal
+		Cost  *decimal
+	}
+	var positional []P
+	wantPositional := []P{{Price: decimal{1, 25}}, {Price: decimal{3, 75}, Cost: &decimal{2, 50}}}
+	if err := b.Query(`select price,cost from t`).ScanPositional(&positional); err != nil || !reflect.DeepEqual(positional, wantPositional) {
+		t.Errorf("ScanPositional got:%+v, err:%v", positional, err)
+	}
+
+	// 非指针、非 nullzero 字段不接收 NULL
+	var one T
+	if err := b.QueryRow(`select id,price from t`).Scan(&one); nil == err || !strings.Contains(err.Error(), "NULL") {
+		t.Errorf("Scan NULL want err, got:%v", err)
+	}
+}
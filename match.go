@@ -0,0 +1,68 @@
+package brows
+
+import (
+	"strings"
+)
+
+// MatchMode 列名和结构体字段 tag 的匹配方式, 可以组合使用.
+//
+// 无论何种方式，都优先使用列名精确匹配 tag
+type MatchMode uint8
+
+const (
+	// MatchExact 列名和 tag 完全一致
+	MatchExact MatchMode = 0
+	// MatchCaseInsensitive 忽略大小写. 若结构体中存在仅大小写不同的 tag, 则这些 tag 只能精确匹配
+	MatchCaseInsensitive MatchMode = 1 << (iota - 1)
+	// MatchStripQualifier 去掉列名的表名(或库名)限定, 如 `user.id` 按 `id` 匹配
+	MatchStripQualifier
+)
+
+// matcher 返回按 mode 查找列名对应结构体字段的方法
+func (mode MatchMode) matcher(m map[string]structField) func(column string) (structField, bool) {
+	exact := func(column string) (structField, bool) {
+		f, ok := m[column]
+		return f, ok
+	}
+	if MatchExact == mode {
+		return exact
+	}
+
+	find := exact
+	if 0 != mode&MatchCaseInsensitive {
+		folded := make(map[string]structField, len(m))
+		ambiguous := make(map[string]bool)
+		for k, v := range m {
+			key := strings.ToLower(k)
+			if _, ok := folded[key]; ok {
+				ambiguous[key] = true
+			}
+			folded[key] = v
+		}
+		for k := range ambiguous {
+			delete(folded, k)
+		}
+
+		find = func(column string) (structField, bool) {
+			if f, ok := m[column]; ok {
+				return f, true
+			}
+			f, ok := folded[strings.ToLower(column)]
+			return f, ok
+		}
+	}
+
+	return func(column string) (structField, bool) {
+		if f, ok := find(column); ok {
+			return f, true
+		}
+
+		if 0 != mode&MatchStripQualifier {
+			if idx := strings.LastIndex(column, "."); idx >= 0 {
+				return find(column[idx+1:])
+			}
+		}
+
+		return structField{}, false
+	}
+}
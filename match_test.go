@@ -0,0 +1,80 @@
+package brows
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchMode_matcher(t *testing.T) {
+	type T struct {
+		ID     int    `db:"id"`
+		Name   string `db:"name"`
+		Upper  string `db:"Code"`
+		Lower  string `db:"code"`
+		Nested string `db:"inner.f1"`
+	}
+
+	m := mapping(reflect.TypeOf(T{}), _tagLabel)
+
+	test := []struct {
+		mode   MatchMode
+		column string
+		want   []int // nil 表示不匹配
+	}{
+		{mode: MatchExact, column: "id", want: []int{0}},
+		{mode: MatchExact, column: "ID", want: nil},
+		{mode: MatchExact, column: "user.id", want: nil},
+		{mode: MatchExact, column: "inner.f1", want: []int{4}},
+
+		{mode: MatchCaseInsensitive, column: "ID", want: []int{0}},
+		{mode: MatchCaseInsensitive, column: "Name", want: []int{1}},
+		{mode: MatchCaseInsensitive, column: "user.id", want: nil},
+		// 仅大小写不同的 tag 只能精确匹配
+		{mode: MatchCaseInsensitive, column: "Code", want: []int{2}},
+		{mode: MatchCaseInsensitive, column: "code", want: []int{3}},
+		{mode: MatchCaseInsensitive, column: "CODE", want: nil},
+
+		{mode: MatchStripQualifier, column: "user.id", want: []int{0}},
+		{mode: MatchStripQualifier, column: "db.user.name", want: []int{1}},
+		{mode: MatchStripQualifier, column: "inner.f1", want: []int{4}},
+		{mode: MatchStripQualifier, column: "user.ID", want: nil},
+
+		{mode: MatchCaseInsensitive | MatchStripQualifier, column: "user.ID", want: []int{0}},
+		{mode: MatchCaseInsensitive | MatchStripQualifier, column: "USER.NAME", want: []int{1}},
+	}
+
+	for _, tt := range test {
+		f, ok := tt.mode.matcher(m)(tt.column)
+		if ok != (tt.want != nil) {
+			t.Errorf("mode:%d, column:%s, got matched:%v", tt.mode, tt.column, ok)
+			continue
+		}
+		if ok && !reflect.DeepEqual(f.index, tt.want) {
+			t.Errorf("mode:%d, column:%s, got index:%v, want:%v", tt.mode, tt.column, f.index, tt.want)
+		}
+	}
+}
+
+func TestMappingByColumns_MatchMode(t *testing.T) {
+	type T struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+
+	var dest T
+	fs := mappingByColumns([]string{"u.ID", "u.NAME", "u.age"}, reflect.ValueOf(&dest),
+		newOptions(WithMatchMode(MatchCaseInsensitive|MatchStripQualifier)))
+	if len(fs) != 3 {
+		t.Fatalf("mappingByColumns got len:%d", len(fs))
+	}
+
+	if fs[0].ignore || fs[0].column != "u.ID" || fs[0].value.Addr().Interface() != &dest.ID {
+		t.Errorf("mappingByColumns got unexpected field:%#v", fs[0])
+	}
+	if fs[1].ignore || fs[1].value.Addr().Interface() != &dest.Name {
+		t.Errorf("mappingByColumns got unexpected field:%#v", fs[1])
+	}
+	if !fs[2].ignore {
+		t.Errorf("mappingByColumns want column u.age ignored")
+	}
+}
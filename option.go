@@ -0,0 +1,68 @@
+package brows
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Option Brows 的配置项
+type Option func(o *options)
+
+type options struct {
+	// 列名和结构体字段 tag 的匹配方式
+	match MatchMode
+	// Scan 前是否检查列类型和字段类型
+	checkColumnTypes bool
+	// 列值为 NULL 时，是否给所有非 nullable 字段赋零值
+	nullZero bool
+	// 生成 SQL 时使用的占位符风格
+	placeholder Placeholder
+	// Page 是否在事务中执行
+	pageTx *sql.TxOptions
+	// 预编译语句缓存的大小, 0 表示不缓存
+	stmtCacheSize int
+	// 查询的重试策略, nil 表示不重试
+	retry *RetryPolicy
+	// 查询的默认超时时间, 0 表示不超时
+	timeout time.Duration
+	// 慢查询日志, nil 表示不记录
+	slowLog *SlowLog
+	// SQL 注释, nil 表示不添加
+	comment *SQLComment
+	// QueryCached 的缓存和并发查询合并
+	cache  Cache
+	flight *flightGroup
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithMatchMode 设置列名和结构体字段 tag 的匹配方式, 默认 MatchExact
+//
+// example:
+//
+//	New(db, WithMatchMode(MatchCaseInsensitive|MatchStripQualifier))
+func WithMatchMode(mode MatchMode) Option {
+	return func(o *options) {
+		o.match = mode
+	}
+}
+
+// WithNullZero 列值为 NULL 时，给不能接收 NULL 的字段(如 string, int 等非指针类型)赋零值, 而不是返回错误.
+//
+// 也可以通过 tag 选项为单个字段开启, 如:
+//
+//	type User struct {
+//		Note string `db:"note,nullzero"`
+//	}
+func WithNullZero() Option {
+	return func(o *options) {
+		o.nullZero = true
+	}
+}
@@ -0,0 +1,102 @@
+package brows
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var ErrPageSize = errors.New("brows: page size must be positive")
+
+// TxBeginner 可以开启事务的 Query, 如 *sql.DB, *sql.Conn
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// WithPageTx 设置 Page 在同一个事务中执行统计和分页查询, 以保证总数和分页数据一致. 仅当 Brows 的 Query 实现了 TxBeginner 时生效
+//
+// example:
+//
+//	New(db, WithPageTx(&sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}))
+func WithPageTx(opts *sql.TxOptions) Option {
+	return func(o *options) {
+		o.pageTx = opts
+		if o.pageTx == nil {
+			o.pageTx = &sql.TxOptions{}
+		}
+	}
+}
+
+// Page 分页查询, 返回 query 的总记录数, 并复制第 page 页(从 1 开始, 每页 size 条)的记录到 dest, dest 要求同 Rows.Scan.
+//
+// 总数通过 select count(*) from (query) 查询; 分页数据通过在 query 后追加 limit/offset 查询,
+// 因此 query 不能包含 limit. 总数为 0 或 page 超出范围时不查询分页数据, dest 为空切片
+//
+// example:
+//
+//	var users []User
+//	total, err := b.Page(ctx, `select id,name from user where age > ? order by id`, []any{10}, 2, 20, &users)
+func (b *Brows) Page(ctx context.Context, query string, args []any, page, size int, dest any) (total int64, err error) {
+	if size <= 0 {
+		return 0, ErrPageSize
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	rv := reflect.ValueOf(dest)
+	if reflect.Pointer != rv.Kind() || rv.IsNil() || reflect.Slice != rv.Elem().Kind() {
+		return 0, ErrScanSliceDestination
+	}
+	// ScanSlice 会追加到已有切片, 先清空
+	rv.Elem().Set(reflect.MakeSlice(rv.Elem().Type(), 0, 0))
+
+	if beginner, ok := b.query.(TxBeginner); ok && b.opts.pageTx != nil {
+		tx, err := beginner.BeginTx(ctx, b.opts.pageTx)
+		if err != nil {
+			return 0, err
+		}
+		defer tx.Rollback()
+
+		total, err = b.with(tx).page(ctx, query, args, page, size, dest)
+		if err != nil {
+			return 0, err
+		}
+
+		return total, tx.Commit()
+	}
+
+	return b.page(ctx, query, args, page, size, dest)
+}
+
+func (b *Brows) page(ctx context.Context, query string, args []any, page, size int, dest any) (int64, error) {
+	query = trimQuery(query)
+
+	var count struct {
+		Total int64
+	}
+	err := b.QueryRowContext(ctx, `select count(*) from (`+query+`) as _brows_page`, args...).ScanPositional(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	offset := int64(page-1) * int64(size)
+	if 0 == count.Total || offset >= count.Total {
+		return count.Total, nil
+	}
+
+	query += " limit " + strconv.Itoa(size) + " offset " + strconv.FormatInt(offset, 10)
+	if err := b.QueryContext(ctx, query, args...).Scan(dest); err != nil {
+		return 0, err
+	}
+
+	return count.Total, nil
+}
+
+// trimQuery 去掉 query 首尾的空白和结尾的分号, 以便作为子查询或追加子句
+func trimQuery(query string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(query), ";"))
+}
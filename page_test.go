@@ -0,0 +1,107 @@
+package brows
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/beanscc/brows/browstest"
+)
+
+func TestBrows_Page(t *testing.T) {
+	type User struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+
+	db := browstest.NewDB(
+		browstest.NewResult("count(*)").AddRow(int64(3)),
+		browstest.NewResult("id", "name").AddRow(int64(3), "c"),
+	)
+	defer db.Close()
+	b := New(db, WithPlaceholder(PlaceholderDollar))
+	ctx := context.Background()
+
+	users := []User{{ID: 100}}
+	total, err := b.Page(ctx, `select id,name from user where age > $1 order by id;`, []any{10}, 2, 2, &users)
+	if err != nil {
+		t.Fatalf("Page err:%v", err)
+	}
+	if total != 3 || len(users) != 1 || users[0].ID != 3 {
+		t.Errorf("Page got total:%v, users:%v", total, users)
+	}
+
+	calls := db.Calls()
+	wantQueries := []string{
+		`select count(*) from (select id,name from user where age > $1 order by id) as _brows_page`,
+		`select id,name from user where age > $1 order by id limit 2 offset 2`,
+	}
+	if len(calls) != len(wantQueries) {
+		t.Fatalf("Page got calls:%v", calls)
+	}
+	for i, v := range wantQueries {
+		if calls[i].Query != v || len(calls[i].Args) != 1 {
+			t.Errorf("Page call[%d] got:%v, want query:%v", i, calls[i], v)
+		}
+	}
+
+	// 超出范围和总数为 0 时不查询分页数据
+	for page, count := range map[int]int64{3: 3, 1: 0} {
+		db.Reset()
+		db.Push(browstest.NewResult("count(*)").AddRow(count))
+		total, err = b.Page(ctx, `select id,name from user`, nil, page, 2, &users)
+		if err != nil || users == nil || len(users) != 0 || len(db.Calls()) != 1 {
+			t.Errorf("Page(%d) got total:%v, users:%v, calls:%v, err:%v", page, total, users, db.Calls(), err)
+		}
+	}
+
+	if _, err := b.Page(ctx, `select id from user`, nil, 1, 0, &users); !errors.Is(err, ErrPageSize) {
+		t.Errorf("Page want ErrPageSize, got:%v", err)
+	}
+	if _, err := b.Page(ctx, `select id from user`, nil, 1, 10, users); !errors.Is(err, ErrScanSliceDestination) {
+		t.Errorf("Page want ErrScanSliceDestination, got:%v", err)
+	}
+}
+
+func TestBrows_Page_Tx(t *testing.T) {
+	db := browstest.NewDB(
+		browstest.NewResult("count(*)").AddRow(int64(1)),
+		browstest.NewResult("id").AddRow(int64(1)),
+	)
+	defer db.Close()
+	b := New(db, WithPageTx(&sql.TxOptions{ReadOnly: true}))
+	ctx := context.Background()
+
+	var ids []struct {
+		ID int64 `db:"id"`
+	}
+	total, err := b.Page(ctx, `select id from user`, nil, 0, 10, &ids)
+	if err != nil || total != 1 || len(ids) != 1 {
+		t.Fatalf("Page got total:%v, ids:%v, err:%v", total, ids, err)
+	}
+
+	var methods []browstest.Method
+	for _, v := range db.Calls() {
+		methods = append(methods, v.Method)
+	}
+	want := []browstest.Method{browstest.MethodBegin, browstest.MethodQuery, browstest.MethodQuery, browstest.MethodCommit}
+	if len(methods) != len(want) {
+		t.Fatalf("Page got methods:%v, want:%v", methods, want)
+	}
+	for i := range want {
+		if methods[i] != want[i] {
+			t.Errorf("Page got methods:%v, want:%v", methods, want)
+			break
+		}
+	}
+
+	db.Reset()
+	db.Push(browstest.NewErrResult(sql.ErrConnDone))
+	if _, err := b.Page(ctx, `select id from user`, nil, 1, 10, &ids); !errors.Is(err, sql.ErrConnDone) {
+		t.Errorf("Page want sql.ErrConnDone, got:%v", err)
+	}
+	if calls := db.Calls(); calls[len(calls)-1].Method != browstest.MethodRollback {
+		t.Errorf("Page want rollback, got calls:%v", calls)
+	}
+}
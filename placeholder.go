@@ -0,0 +1,54 @@
+package brows
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Placeholder SQL 参数占位符风格
+type Placeholder uint8
+
+const (
+	// PlaceholderQuestion ? 占位符, 如 MySQL, SQLite
+	PlaceholderQuestion Placeholder = iota
+	// PlaceholderDollar $1, $2 占位符, 如 PostgreSQL
+	PlaceholderDollar
+)
+
+// WithPlaceholder 设置 Brows 生成 SQL(如 Select 构建的查询) 时使用的占位符风格, 默认 PlaceholderQuestion
+func WithPlaceholder(p Placeholder) Option {
+	return func(o *options) {
+		o.placeholder = p
+	}
+}
+
+// rebind 将 query 中的 ? 占位符转换为 p 风格, 引号内的 ? 不做转换
+func (p Placeholder) rebind(query string) string {
+	if PlaceholderQuestion == p || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var (
+		b     strings.Builder
+		n     int
+		quote rune
+	)
+	b.Grow(len(query) + 8)
+	for _, r := range query {
+		switch {
+		case 0 != quote:
+			if r == quote {
+				quote = 0
+			}
+		case '\'' == r || '"' == r || '`' == r:
+			quote = r
+		case '?' == r:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
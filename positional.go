@@ -0,0 +1,212 @@
+package brows
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	ErrPositionalDestination = errors.New("brows: positional destination must be a non-nil pointer to a struct, array or slice")
+	ErrColumnCount           = errors.New("brows: column count not match destination")
+)
+
+// ScanPositional 读取第一行记录，按列的顺序复制到 dest, 不依赖 tag 和列名.
+//
+// dest 支持以下类型:
+//   - *struct: 按结构体字段的声明顺序依次赋值, 字段数量需和列数量一致
+//   - *[N]T: 按数组下标依次赋值, 数组长度需和列数量一致
+//   - *[]T: 切片长度将被调整为列数量，然后按下标依次赋值
+//
+// 结构体字段的提取规则同 mapping, 但不要求字段有 tag:
+//   - 不可导出字段 和 tag 为 '-' 的字段将被忽略
+//   - 非叶子类型(见 RegisterLeafType)的结构体或结构体指针, 包括匿名内嵌对象, 将展开其内部字段
+//
+// 若 Rows 无记录，则返回 sql.ErrNoRows 错误; 若有多条记录，只读取第一条.
+//
+// example:
+//
+//	type Stat struct {
+//		Total int64
+//		Sum   float64
+//	}
+//
+//	var stat Stat
+//	ScanPositional(rows, &stat) // select count(*), sum(amount) from ...
+//
+//	var values [2]any
+//	ScanPositional(rows, &values)
+func ScanPositional(rows *sql.Rows, dest any) error {
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+
+	rv := reflect.ValueOf(dest)
+	if reflect.Pointer != rv.Kind() || rv.IsNil() {
+		return ErrPositionalDestination
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	values, err := positionalValues(len(columns), rv.Elem())
+	if err != nil {
+		return err
+	}
+
+	if err := rows.Scan(values...); err != nil {
+		return err
+	}
+	if err := afterScan(rv); err != nil {
+		return err
+	}
+
+	return rows.Close()
+}
+
+// ScanSlicePositional 读取所有行记录，按列的顺序复制到 dest.
+// dest 必须是切片的指针, 切片元素支持 struct, *struct, [N]T 和 []T, 每一行的赋值规则同 ScanPositional
+//
+// example:
+//
+//	var records [][]any
+//	ScanSlicePositional(rows, &records)
+func ScanSlicePositional(rows *sql.Rows, dest any) error {
+	defer rows.Close()
+
+	rv := reflect.ValueOf(dest)
+	if reflect.Pointer != rv.Kind() || rv.IsNil() {
+		return ErrScanSliceDestination
+	}
+
+	slice := rv.Elem()
+	if reflect.Slice != slice.Kind() {
+		return ErrScanSliceDestination
+	}
+
+	sliceElemType := slice.Type().Elem()
+	sliceElemInnerType := sliceElemType
+	if reflect.Pointer == sliceElemType.Kind() {
+		sliceElemInnerType = sliceElemType.Elem()
+	}
+	switch sliceElemInnerType.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Slice:
+	default:
+		return ErrSliceElement
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		one := reflect.New(sliceElemInnerType)
+		values, err := positionalValues(len(columns), one.Elem())
+		if err != nil {
+			return err
+		}
+		if err := rows.Scan(values...); err != nil {
+			return err
+		}
+		if err := afterScan(one); err != nil {
+			return err
+		}
+		if reflect.Pointer != sliceElemType.Kind() {
+			one = one.Elem()
+		}
+		slice = reflect.Append(slice, one)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rv.Elem().Set(slice)
+	return rows.Close()
+}
+
+// positionalValues 按顺序返回 rv 中用于 rows.Scan 的 n 个目标地址. rv 需可寻址
+func positionalValues(n int, rv reflect.Value) ([]any, error) {
+	switch rv.Kind() {
+	case reflect.Struct:
+		indexes := positional(rv.Type())
+		if len(indexes) != n {
+			return nil, fmt.Errorf("%w: columns %d, fields %d", ErrColumnCount, n, len(indexes))
+		}
+
+		out := make([]any, 0, n)
+		for _, index := range indexes {
+			out = append(out, scanTarget(fieldByIndexAlloc(rv, index)))
+		}
+		return out, nil
+	case reflect.Array:
+		if rv.Len() != n {
+			return nil, fmt.Errorf("%w: columns %d, array length %d", ErrColumnCount, n, rv.Len())
+		}
+	case reflect.Slice:
+		if rv.Len() != n {
+			rv.Set(reflect.MakeSlice(rv.Type(), n, n))
+		}
+	default:
+		return nil, ErrPositionalDestination
+	}
+
+	out := make([]any, 0, n)
+	for i := 0; i < n; i++ {
+		out = append(out, scanTarget(rv.Index(i)))
+	}
+	return out, nil
+}
+
+// positional 按声明顺序提取 reflect.Type 对象中用于赋值的字段索引
+func positional(rt reflect.Type) (out [][]int) {
+	if reflect.Pointer == rt.Kind() {
+		rt = rt.Elem()
+	}
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if nested(field.Type) {
+			for _, index := range positional(field.Type) {
+				out = append(out, append([]int{i}, index...))
+			}
+			continue
+		}
+
+		tagValue, _ := head(field.Tag.Get(_tagLabel), ",")
+		if "-" == tagValue {
+			continue
+		}
+
+		out = append(out, []int{i})
+	}
+
+	return out
+}
+
+// fieldByIndexAlloc 同 reflect.Value.FieldByIndex, 但会为路径上的 nil 指针分配对象
+func fieldByIndexAlloc(rv reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && reflect.Pointer == rv.Kind() {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+
+	return rv
+}
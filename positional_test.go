@@ -0,0 +1,98 @@
+package brows
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPositional(t *testing.T) {
+	type Inner struct {
+		F1 string
+		F2 *int
+	}
+
+	type T struct {
+		Total int64
+		Sum   float64 `db:"sum"`
+
+		// ignore
+		F3 int `db:"-"`
+		f4 string
+
+		At time.Time
+
+		Inner
+		Nested *Inner
+	}
+
+	got := positional(reflect.TypeOf(T{}))
+	want := [][]int{{0}, {1}, {4}, {5, 0}, {5, 1}, {6, 0}, {6, 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TestPositional got:%v, want:%v", got, want)
+	}
+}
+
+func TestPositionalValues(t *testing.T) {
+	type Inner struct {
+		F1 string
+	}
+
+	type T struct {
+		Total int64
+		*Inner
+	}
+
+	t.Run("struct", func(t *testing.T) {
+		var dest T
+		values, err := positionalValues(2, reflect.ValueOf(&dest).Elem())
+		if err != nil {
+			t.Fatalf("positionalValues err:%v", err)
+		}
+
+		if dest.Inner == nil {
+			t.Fatal("positionalValues want nested pointer allocated")
+		}
+		if values[0] != &dest.Total || values[1] != &dest.Inner.F1 {
+			t.Errorf("positionalValues got unexpected values:%v", values)
+		}
+
+		if _, err := positionalValues(3, reflect.ValueOf(&dest).Elem()); !errors.Is(err, ErrColumnCount) {
+			t.Errorf("positionalValues want ErrColumnCount, got:%v", err)
+		}
+	})
+
+	t.Run("array", func(t *testing.T) {
+		var dest [2]any
+		values, err := positionalValues(2, reflect.ValueOf(&dest).Elem())
+		if err != nil {
+			t.Fatalf("positionalValues err:%v", err)
+		}
+		if values[1] != &dest[1] {
+			t.Errorf("positionalValues got unexpected values:%v", values)
+		}
+
+		if _, err := positionalValues(1, reflect.ValueOf(&dest).Elem()); !errors.Is(err, ErrColumnCount) {
+			t.Errorf("positionalValues want ErrColumnCount, got:%v", err)
+		}
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		var dest []any
+		values, err := positionalValues(3, reflect.ValueOf(&dest).Elem())
+		if err != nil {
+			t.Fatalf("positionalValues err:%v", err)
+		}
+		if len(dest) != 3 || values[2] != &dest[2] {
+			t.Errorf("positionalValues got unexpected values:%v, dest:%v", values, dest)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		var dest int
+		if _, err := positionalValues(1, reflect.ValueOf(&dest).Elem()); !errors.Is(err, ErrPositionalDestination) {
+			t.Errorf("positionalValues want ErrPositionalDestination, got:%v", err)
+		}
+	})
+}
@@ -3,6 +3,8 @@ package brows
 import (
 	"context"
 	"database/sql"
+	"reflect"
+	"time"
 )
 
 type Query interface {
@@ -11,15 +13,50 @@ type Query interface {
 
 type Brows struct {
 	query Query
+	opts  *options
+	// stmts 预编译语句缓存, 未开启时为 nil
+	stmts *stmtCache
+	// tx query 为 *sql.Tx 且共用 stmts 时, 用于绑定缓存的语句
+	tx *sql.Tx
 }
 
 // New return new Brows
 //
 // query could be *sql.DB, *sql.Tx, *sql.Conn or other object who implemented Query interface
-func New(query Query) *Brows {
-	return &Brows{
+func New(query Query, opts ...Option) *Brows {
+	b := &Brows{
 		query: query,
+		opts:  newOptions(opts...),
 	}
+	if b.opts.stmtCacheSize > 0 {
+		// *sql.Tx 预编译的语句在事务结束后不可用, 不缓存
+		if preparer, ok := query.(Preparer); ok {
+			if _, isTx := query.(*sql.Tx); !isTx {
+				b.stmts = newStmtCache(preparer, b.opts.stmtCacheSize)
+			}
+		}
+	}
+
+	return b
+}
+
+// with 返回使用 query 查询的 Brows, 配置和语句缓存与 b 相同
+func (b *Brows) with(query Query) *Brows {
+	nb := &Brows{
+		query: query,
+		opts:  b.opts,
+		stmts: b.stmts,
+	}
+	if nil != b.stmts {
+		if tx, ok := query.(*sql.Tx); ok {
+			nb.tx = tx
+		} else {
+			// 其他 Query 无法绑定缓存的语句
+			nb.stmts = nil
+		}
+	}
+
+	return nb
 }
 
 func (b *Brows) QueryRow(query string, args ...any) *Row {
@@ -35,8 +72,46 @@ func (b *Brows) Query(query string, args ...any) *Rows {
 }
 
 func (b *Brows) QueryContext(ctx context.Context, query string, args ...any) *Rows {
-	rows, err := b.query.QueryContext(ctx, query, args...)
-	return &Rows{err: err, rows: rows}
+	var (
+		start time.Time
+		at    string
+	)
+	if nil != b.opts.slowLog {
+		start = time.Now()
+		// 读取可能在其他 goroutine 中完成(如 ScanBatches), 在发起查询时记录调用位置
+		at = queryCaller(ctx)
+	}
+	ctx, cancel, timeout := b.withTimeout(ctx)
+	sqlText := query
+	// dynamic 注释随请求变化, 预编译的语句无法复用, 不使用语句缓存
+	var dynamic bool
+	if nil != b.opts.comment {
+		sqlText, dynamic = b.opts.comment.apply(ctx, query)
+	}
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	exec := func() error {
+		if nil != b.stmts && !dynamic {
+			rows, err = b.queryStmt(ctx, sqlText, args...)
+		} else {
+			rows, err = b.query.QueryContext(ctx, sqlText, args...)
+		}
+		return err
+	}
+	// 事务中的错误(如死锁)可能已回滚整个事务, 只能通过 RetryTx 重试
+	if nil != b.opts.retry && !b.inTx() {
+		err = b.opts.retry.do(ctx, exec)
+	} else {
+		exec()
+	}
+
+	rs := &Rows{rows: rows, opts: b.opts, query: query, args: args, start: start, caller: at, ctx: ctx, cancel: cancel, timeout: timeout}
+	if err != nil {
+		rs.err = rs.finish(err)
+	}
+	return rs
 }
 
 type Row struct {
@@ -52,16 +127,51 @@ func (r *Row) Scan(dest any) error {
 		return err
 	}
 
-	return Scan(r.rows.rows, dest)
+	return r.rows.finishOne(scan(r.rows.rows, dest, r.rows.opts, false))
+}
+
+// ScanOne 复制唯一一行记录到 dest, 若有多条记录则返回 ErrTooManyRows, 详见 ScanOne
+func (r *Row) ScanOne(dest any) error {
+	if err := r.rows.err; err != nil {
+		return err
+	}
+
+	return r.rows.finishOne(scan(r.rows.rows, dest, r.rows.opts, true))
+}
+
+// ScanPositional 按列的顺序复制第一行记录到 dest, 详见 ScanPositional
+func (r *Row) ScanPositional(dest any) error {
+	if err := r.rows.err; err != nil {
+		return err
+	}
+
+	return r.rows.finishOne(ScanPositional(r.rows.rows, dest))
 }
 
 type Rows struct {
 	err  error
 	rows *sql.Rows
+	opts *options
+
+	// query, args 查询的 SQL 和参数, 用于超时错误和慢查询日志
+	query string
+	args  []any
+	// start 开始查询的时间, caller 发起查询的位置, scanned 读取的行数, 用于慢查询日志
+	start   time.Time
+	caller  string
+	scanned int
+	// finished 是否已结束查询
+	finished bool
+	// hint Scan 时预分配的切片容量
+	hint int
+	// ctx 查询使用的 ctx, cancel 不为 nil 时在读取完成或 Close 时调用
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
 }
 
 func (rs *Rows) Close() error {
-	return rs.rows.Close()
+	return rs.finish(rs.rows.Close())
 }
 
 func (rs *Rows) ColumnTypes() ([]*sql.ColumnType, error) {
@@ -72,6 +182,17 @@ func (rs *Rows) Columns() ([]string, error) {
 	return rs.rows.Columns()
 }
 
+// SizeHint 设置预计的行数, Scan 时按 n 预分配切片容量, 减少扩容.
+// dest 为 []*T 时, 元素按批(最多 256 个)分配, 同一批的元素共享底层数组, 保留其中一个元素即保留整批
+//
+// example:
+//
+//	err := b.Query(`select id,name from user limit 1000`).SizeHint(1000).Scan(&users)
+func (rs *Rows) SizeHint(n int) *Rows {
+	rs.hint = n
+	return rs
+}
+
 func (rs *Rows) Err() error {
 	return rs.err
 }
@@ -80,5 +201,29 @@ func (rs *Rows) Scan(dest any) error {
 	if rs.err != nil {
 		return rs.err
 	}
-	return ScanSlice(rs.rows, dest)
+	n := sliceLen(dest)
+	err := scanSlice(rs.rows, dest, rs.opts, rs.hint)
+	rs.scanned += sliceLen(dest) - n
+	return rs.finish(err)
+}
+
+// ScanPositional 按列的顺序复制所有行记录到 dest, 详见 ScanSlicePositional
+func (rs *Rows) ScanPositional(dest any) error {
+	if rs.err != nil {
+		return rs.err
+	}
+	n := sliceLen(dest)
+	err := ScanSlicePositional(rs.rows, dest)
+	rs.scanned += sliceLen(dest) - n
+	return rs.finish(err)
+}
+
+// sliceLen 返回 dest 指向的切片的长度, dest 不是切片指针时返回 0
+func sliceLen(dest any) int {
+	rv := reflect.ValueOf(dest)
+	if reflect.Pointer != rv.Kind() || rv.IsNil() || reflect.Slice != rv.Elem().Kind() {
+		return 0
+	}
+
+	return rv.Elem().Len()
 }
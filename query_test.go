@@ -4,11 +4,13 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"os"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/beanscc/brows/browstest"
 	_ "github.com/go-sql-driver/mysql"
 )
 
@@ -29,9 +31,13 @@ func (dbt *DBTest) mustExec(query string, args ...any) sql.Result {
 	return res
 }
 
+// testDBScope 在 MySQL 上执行 fn. MySQL 的 dsn 可通过环境变量 BROWS_TEST_DSN 设置, 若 MySQL 不可用则跳过测试
 func testDBScope(t *testing.T, fn func(dbt *DBTest)) {
 	// init db
-	dsn := `root:P4m@bpet@tcp(127.0.0.1:3306)/test?charset=utf8mb4&parseTime=true&loc=UTC&readTimeout=3s&timeout=3s&writeTimeout=3s`
+	dsn := os.Getenv("BROWS_TEST_DSN")
+	if "" == dsn {
+		dsn = `root:P4m@bpet@tcp(127.0.0.1:3306)/test?charset=utf8mb4&parseTime=true&loc=UTC&readTimeout=3s&timeout=3s&writeTimeout=3s`
+	}
 	fmt.Println("dsn:", dsn)
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
@@ -39,7 +45,7 @@ func testDBScope(t *testing.T, fn func(dbt *DBTest)) {
 	}
 
 	if err := db.Ping(); err != nil {
-		t.Fatal(err)
+		t.Skipf("mysql unavailable: %v", err)
 	}
 
 	dbt := DBTest{
@@ -372,3 +378,182 @@ CREATE TABLE test_brows (
 		}
 	})
 }
+
+func TestBrows_QueryRow_ScanOne(t *testing.T) {
+	testDBScope(t, func(dbt *DBTest) {
+		dbt.mustExec(`CREATE TABLE test_brows (id int not null primary key, name varchar(255))`)
+		dbt.mustExec(`insert into test_brows values (1, 'a'), (2, 'b')`)
+
+		type Out struct {
+			ID   int    `db:"id"`
+			Name string `db:"name"`
+		}
+
+		brows := New(dbt.db)
+
+		var out Out
+		if err := brows.QueryRow(`select id,name from test_brows where id = ?`, 1).ScanOne(&out); err != nil {
+			t.Errorf("TestBrows_QueryRow_ScanOne err:%v", err)
+			return
+		}
+		if out.ID != 1 || out.Name != "a" {
+			t.Errorf("TestBrows_QueryRow_ScanOne got unexpected out:%#v", out)
+		}
+
+		err := brows.QueryRow(`select id,name from test_brows where id = ?`, 100).ScanOne(&out)
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("TestBrows_QueryRow_ScanOne want sql.ErrNoRows, got:%v", err)
+		}
+
+		err = brows.QueryRow(`select id,name from test_brows order by id`).ScanOne(&out)
+		if !errors.Is(err, ErrTooManyRows) {
+			t.Errorf("TestBrows_QueryRow_ScanOne want ErrTooManyRows, got:%v", err)
+		}
+	})
+}
+
+func TestBrows_Fake(t *testing.T) {
+	type Inner struct {
+		Email *string `db:"email"`
+	}
+
+	type User struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+		Age  uint   `db:"age"`
+		*Inner
+	}
+
+	users := func() *browstest.Result {
+		return browstest.NewResult("id", "name", "age", "email", "other").
+			AddRow(int64(1), "a", int64(10), "a@b.c", "x").
+			AddRow(int64(2), "b", int64(20), nil, "y")
+	}
+
+	db := browstest.NewDB(users(), users(), users(), browstest.NewErrResult(sql.ErrConnDone))
+	defer db.Close()
+	b := New(db)
+
+	var user User
+	if err := b.QueryRow(`select * from user where id = ?`, 1).Scan(&user); err != nil {
+		t.Fatalf("QueryRow err:%v", err)
+	}
+	if user.ID != 1 || user.Name != "a" || user.Age != 10 || user.Inner == nil || *user.Email != "a@b.c" {
+		t.Errorf("QueryRow got unexpected user:%#v", user)
+	}
+
+	var list []User
+	if err := b.Query(`select * from user`).Scan(&list); err != nil {
+		t.Fatalf("Query err:%v", err)
+	}
+	if len(list) != 2 || list[1].Name != "b" || list[1].Email != nil {
+		t.Errorf("Query got unexpected users:%#v", list)
+	}
+
+	if err := b.QueryRow(`select * from user`).ScanOne(&user); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("QueryRow.ScanOne want ErrTooManyRows, got:%v", err)
+	}
+
+	if err := b.QueryRow(`select * from user`).Scan(&user); !errors.Is(err, sql.ErrConnDone) {
+		t.Errorf("QueryRow want sql.ErrConnDone, got:%v", err)
+	}
+
+	calls := db.Calls()
+	if len(calls) != 4 || !reflect.DeepEqual(calls[0].Args, []any{int64(1)}) {
+		t.Errorf("Calls got unexpected calls:%v", calls)
+	}
+}
+
+func TestBrows_Fake_NoRows(t *testing.T) {
+	db := browstest.NewDB(browstest.NewResult("id"), browstest.NewResult("id").AddRow(int64(1)))
+	defer db.Close()
+	b := New(db)
+
+	var out struct {
+		ID int64 `db:"id"`
+	}
+	if err := b.QueryRow(`select id from user`).ScanOne(&out); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("QueryRow.ScanOne want sql.ErrNoRows, got:%v", err)
+	}
+	if err := b.QueryRow(`select id from user`).ScanOne(&out); err != nil || out.ID != 1 {
+		t.Errorf("QueryRow.ScanOne got out:%v, err:%v", out, err)
+	}
+}
+
+func TestBrows_Fake_Positional(t *testing.T) {
+	stat := func() *browstest.Result {
+		return browstest.NewResult("count(*)", "sum(age)").AddRow(int64(2), 30.5).AddRow(int64(3), 1.5)
+	}
+	db := browstest.NewDB(stat(), stat(), stat())
+	defer db.Close()
+	b := New(db)
+
+	var one struct {
+		Count int64
+		Sum   float64
+	}
+	if err := b.QueryRow(`select count(*), sum(age) from user`).ScanPositional(&one); err != nil {
+		t.Fatalf("ScanPositional err:%v", err)
+	}
+	if one.Count != 2 || one.Sum != 30.5 {
+		t.Errorf("ScanPositional got:%#v", one)
+	}
+
+	var values [][2]any
+	if err := b.Query(`select count(*), sum(age) from user`).ScanPositional(&values); err != nil {
+		t.Fatalf("ScanPositional err:%v", err)
+	}
+	if len(values) != 2 || values[1][0] != int64(3) || values[1][1] != 1.5 {
+		t.Errorf("ScanPositional got:%v", values)
+	}
+
+	var mismatch [3]any
+	if err := b.QueryRow(`select count(*), sum(age) from user`).ScanPositional(&mismatch); !errors.Is(err, ErrColumnCount) {
+		t.Errorf("ScanPositional want ErrColumnCount, got:%v", err)
+	}
+}
+
+func TestBrows_Fake_NullZero(t *testing.T) {
+	result := func() *browstest.Result {
+		return browstest.NewResult("name", "note").AddRow(nil, "a").AddRow("b", nil)
+	}
+	db := browstest.NewDB(result(), result())
+	defer db.Close()
+
+	type Out struct {
+		Name string `db:"name,nullzero"`
+		Note string `db:"note"`
+	}
+
+	var out []Out
+	if err := New(db).Query(`select name,note from user`).Scan(&out); err == nil {
+		t.Error("Query want error scanning NULL into note")
+	}
+
+	out = nil
+	if err := New(db, WithNullZero()).Query(`select name,note from user`).Scan(&out); err != nil {
+		t.Fatalf("Query err:%v", err)
+	}
+	if want := []Out{{Name: "", Note: "a"}, {Name: "b", Note: ""}}; !reflect.DeepEqual(out, want) {
+		t.Errorf("Query got:%v, want:%v", out, want)
+	}
+}
+
+func TestBrows_Fake_ColumnTypeCheck(t *testing.T) {
+	r := browstest.NewResult().AddRow(int64(1), nil)
+	r.Columns = []browstest.Column{
+		{Name: "id", DatabaseType: "BIGINT"},
+		{Name: "age", DatabaseType: "INT", Nullable: true, ScanType: reflect.TypeOf(sql.NullInt64{})},
+	}
+	db := browstest.NewDB(r)
+	defer db.Close()
+
+	var out struct {
+		ID  int64 `db:"id"`
+		Age uint  `db:"age"`
+	}
+	err := New(db, WithColumnTypeCheck()).QueryRow(`select id,age from user`).Scan(&out)
+	if !errors.Is(err, ErrColumnType) || !strings.Contains(err.Error(), "column age is nullable but field Age is uint") {
+		t.Errorf("QueryRow want ErrColumnType, got:%v", err)
+	}
+}
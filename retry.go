@@ -0,0 +1,164 @@
+package brows
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var ErrTxUnsupported = errors.New("brows: query does not implement TxBeginner")
+
+// RetryPolicy 临时错误的重试策略
+type RetryPolicy struct {
+	// MaxAttempts 最多尝试的次数(包括第一次), 小于等于 1 时不重试
+	MaxAttempts int
+	// BaseDelay 第一次重试前等待时间的上限, 之后每次翻倍, 实际等待时间在 [0, 上限) 中随机. 默认 10ms
+	BaseDelay time.Duration
+	// MaxDelay 等待时间上限的最大值, 默认 1s
+	MaxDelay time.Duration
+	// Retryable 判断错误是否可以重试, 默认 MySQLRetryable
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy RetryTx 在未通过 WithRetry 设置重试策略时使用的策略
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   10 * time.Millisecond,
+	MaxDelay:    time.Second,
+	Retryable:   MySQLRetryable,
+}
+
+// WithRetry 设置查询(Query/QueryRow 等)和 RetryTx 的重试策略. 仅重试执行查询时的错误, 不重试读取和 Scan 时的错误;
+// ctx 结束或等待将超过 ctx 的截止时间时不再重试. 在事务中(New(tx), WithTx)的查询不重试, 因为死锁等错误已回滚整个事务, 需使用 RetryTx
+//
+// example:
+//
+//	New(db, WithRetry(RetryPolicy{MaxAttempts: 3}))
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = &policy
+	}
+}
+
+// MySQLRetryable 判断 err 是否为可以重试的 MySQL 临时错误: driver.ErrBadConn, mysql.ErrInvalidConn,
+// 死锁(1213)和锁等待超时(1205)
+func MySQLRetryable(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) {
+		return true
+	}
+
+	var me *mysql.MySQLError
+	if errors.As(err, &me) {
+		switch me.Number {
+		case 1213, 1205:
+			return true
+		}
+	}
+
+	return false
+}
+
+// RetryTx 在事务中执行 fn, fn 返回 nil 时提交, 否则回滚. 开启事务或 fn 返回可以重试的错误时, 按重试策略(默认 DefaultRetryPolicy)
+// 开启新的事务重新执行 fn, 因此 fn 需要可以重复执行. 提交返回的错误不重试: 如连接断开时提交可能已生效, 重新执行 fn 将重复修改数据.
+// Brows 的 Query 需实现 TxBeginner
+//
+// example:
+//
+//	err := b.RetryTx(ctx, func(tx *sql.Tx) error {
+//		_, err := tx.ExecContext(ctx, `update account set balance = balance - ? where id = ?`, 10, 1)
+//		return err
+//	})
+func (b *Brows) RetryTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	beginner, ok := b.query.(TxBeginner)
+	if !ok {
+		return ErrTxUnsupported
+	}
+
+	policy := b.opts.retry
+	if nil == policy {
+		policy = &DefaultRetryPolicy
+	}
+
+	var commitErr error
+	err := policy.do(ctx, func() error {
+		tx, err := beginner.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if err := fn(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		commitErr = tx.Commit()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return commitErr
+}
+
+// inTx 判断 b 是否在事务中查询
+func (b *Brows) inTx() bool {
+	if nil != b.tx {
+		return true
+	}
+	_, ok := b.query.(*sql.Tx)
+	return ok
+}
+
+// do 执行 fn, 按策略重试可以重试的错误, 返回最后一次的错误
+func (p *RetryPolicy) do(ctx context.Context, fn func() error) error {
+	retryable := p.Retryable
+	if nil == retryable {
+		retryable = MySQLRetryable
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if nil == err || attempt >= p.MaxAttempts || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := p.delay(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// delay 返回第 attempt 次尝试失败后的等待时间
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	base, max := p.BaseDelay, p.MaxDelay
+	if base <= 0 {
+		base = 10 * time.Millisecond
+	}
+	if max <= 0 {
+		max = time.Second
+	}
+
+	ceil := base
+	for i := 1; i < attempt && ceil < max; i++ {
+		ceil *= 2
+	}
+	if ceil > max {
+		ceil = max
+	}
+
+	return time.Duration(rand.Int63n(int64(ceil)))
+}
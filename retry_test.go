@@ -0,0 +1,169 @@
+package brows
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/beanscc/brows/browstest"
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestMySQLRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{driver.ErrBadConn, true},
+		{fmt.Errorf("wrap: %w", mysql.ErrInvalidConn), true},
+		{&mysql.MySQLError{Number: 1213}, true},
+		{fmt.Errorf("wrap: %w", &mysql.MySQLError{Number: 1205}), true},
+		{&mysql.MySQLError{Number: 1062}, false},
+		{sql.ErrNoRows, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := MySQLRetryable(tt.err); got != tt.want {
+			t.Errorf("MySQLRetryable(%v) got:%v, want:%v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 25 * time.Millisecond}
+	for attempt, ceil := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 5: 25 * time.Millisecond} {
+		for i := 0; i < 100; i++ {
+			if d := p.delay(attempt); d < 0 || d >= ceil {
+				t.Fatalf("delay(%d) got:%v, want [0, %v)", attempt, d, ceil)
+			}
+		}
+	}
+}
+
+func TestBrows_Retry(t *testing.T) {
+	deadlock := &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}
+	db := browstest.NewDB(
+		browstest.NewErrResult(deadlock),
+		browstest.NewResult("id").AddRow(int64(1)),
+		browstest.NewErrResult(deadlock),
+		browstest.NewErrResult(deadlock),
+		browstest.NewErrResult(sql.ErrConnDone),
+	)
+	defer db.Close()
+	b := New(db, WithRetry(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+	ctx := context.Background()
+
+	var out struct {
+		ID int64 `db:"id"`
+	}
+	if err := b.QueryRowContext(ctx, `select id from user`).Scan(&out); err != nil || out.ID != 1 {
+		t.Errorf("QueryRow got:%v, err:%v", out, err)
+	}
+	if err := b.QueryRowContext(ctx, `select id from user`).Scan(&out); !errors.Is(err, deadlock) {
+		t.Errorf("QueryRow want deadlock after MaxAttempts, got:%v", err)
+	}
+	if err := b.QueryRowContext(ctx, `select id from user`).Scan(&out); !errors.Is(err, sql.ErrConnDone) {
+		t.Errorf("QueryRow want sql.ErrConnDone without retry, got:%v", err)
+	}
+	if n := countCalls(db, browstest.MethodQuery); n != 5 {
+		t.Errorf("query got:%v, want:5", n)
+	}
+
+	// 等待超过 ctx 的截止时间时不再重试
+	db.Reset()
+	db.Push(browstest.NewErrResult(deadlock), browstest.NewResult("id").AddRow(int64(1)))
+	b = New(db, WithRetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}))
+	tctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	if err := b.QueryRowContext(tctx, `select id from user`).Scan(&out); !errors.Is(err, deadlock) {
+		t.Errorf("QueryRow with deadline want deadlock, got:%v", err)
+	}
+}
+
+func TestBrows_RetryTx(t *testing.T) {
+	deadlock := &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}
+	db := browstest.NewDB(browstest.NewErrResult(deadlock), browstest.NewExecResult(0, 1))
+	defer db.Close()
+	b := New(db)
+	ctx := context.Background()
+
+	attempts := 0
+	err := b.RetryTx(ctx, func(tx *sql.Tx) error {
+		attempts++
+		_, err := tx.ExecContext(ctx, `update account set balance = balance - ? where id = ?`, 10, 1)
+		return err
+	})
+	if err != nil || attempts != 2 {
+		t.Errorf("RetryTx got attempts:%v, err:%v", attempts, err)
+	}
+
+	var methods []browstest.Method
+	for _, v := range db.Calls() {
+		methods = append(methods, v.Method)
+	}
+	want := []browstest.Method{
+		browstest.MethodBegin, browstest.MethodExec, browstest.MethodRollback,
+		browstest.MethodBegin, browstest.MethodExec, browstest.MethodCommit,
+	}
+	if fmt.Sprint(methods) != fmt.Sprint(want) {
+		t.Errorf("RetryTx got methods:%v, want:%v", methods, want)
+	}
+
+	errFn := errors.New("fn")
+	if err := b.RetryTx(ctx, func(tx *sql.Tx) error { return errFn }); !errors.Is(err, errFn) {
+		t.Errorf("RetryTx want errFn, got:%v", err)
+	}
+
+	// 提交时连接断开, 提交可能已生效, 不重试
+	db.Reset()
+	db.SetCommitError(mysql.ErrInvalidConn)
+	attempts = 0
+	if err := b.RetryTx(ctx, func(tx *sql.Tx) error { attempts++; return nil }); !errors.Is(err, mysql.ErrInvalidConn) || attempts != 1 {
+		t.Errorf("RetryTx commit error got attempts:%v, err:%v", attempts, err)
+	}
+	db.SetCommitError(nil)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin err:%v", err)
+	}
+	defer tx.Rollback()
+	if err := New(struct{ Query }{tx}).RetryTx(ctx, func(tx *sql.Tx) error { return nil }); !errors.Is(err, ErrTxUnsupported) {
+		t.Errorf("RetryTx want ErrTxUnsupported, got:%v", err)
+	}
+}
+
+func TestBrows_Retry_Tx(t *testing.T) {
+	deadlock := &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}
+	db := browstest.NewDB()
+	defer db.Close()
+	ctx := context.Background()
+	policy := WithRetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	var out struct {
+		ID int64 `db:"id"`
+	}
+	for name, bind := range map[string]func(tx *sql.Tx) *Brows{
+		"New(tx)":       func(tx *sql.Tx) *Brows { return New(tx, policy) },
+		"WithTx":        func(tx *sql.Tx) *Brows { return New(db, policy).WithTx(tx) },
+		"WithTx(stmts)": func(tx *sql.Tx) *Brows { return New(db, policy, WithStmtCache(8)).WithTx(tx) },
+	} {
+		db.Reset()
+		db.Push(browstest.NewErrResult(deadlock), browstest.NewResult("id").AddRow(int64(1)))
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("BeginTx err:%v", err)
+		}
+		if err := bind(tx).QueryRowContext(ctx, `select id from user`).Scan(&out); !errors.Is(err, deadlock) {
+			t.Errorf("%s want deadlock without retry, got:%v", name, err)
+		}
+		if n := countCalls(db, browstest.MethodQuery); n != 1 {
+			t.Errorf("%s query got:%v, want:1", name, n)
+		}
+		tx.Rollback()
+	}
+}
@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 )
 
 var (
 	ErrScanDestination      = errors.New("brows: Scan destination must be a non-nil pointer to a struct")
 	ErrScanSliceDestination = errors.New("brows: ScanSlice destination must be a non-nil pointer to a slice")
 	ErrSliceElement         = errors.New("brows: slice element only support *struct or struct")
+	ErrTooManyRows          = errors.New("brows: too many rows in result set")
 )
 
 // Scan 读取第一行记录，复制到 dest. dest 必须是 *struct.
@@ -19,7 +21,7 @@ var (
 // 结构体字段通过 tag 和 columns 进行唯一匹配，不依赖 columns 和结构体字段顺序.
 // 内部转换复制依赖 `database/sql` 包的 Rows.Scan 方法
 //
-//   - 若 Rows 有多条记录，只读取第一条，丢弃其他剩余记录;
+//   - 若 Rows 有多条记录，只读取第一条，丢弃其他剩余记录; 若需要确保只有一条记录，使用 ScanOne;
 //   - 若 Rows 无记录，则返回 sql.ErrNoRows 错误;
 //
 // example:
@@ -32,6 +34,21 @@ var (
 //	var user User
 //	Scan(rows, &user)
 func Scan(rows *sql.Rows, dest any) error {
+	return scan(rows, dest, newOptions(), false)
+}
+
+// ScanOne 同 Scan, 但要求 Rows 有且只有一条记录:
+//
+//   - 若 Rows 无记录，则返回 sql.ErrNoRows 错误;
+//   - 若 Rows 有多条记录，则返回 ErrTooManyRows 错误, 此时 dest 已被赋值为第一条记录;
+//
+// 适用于通过唯一键查询的场景，以便尽早发现缺少唯一索引等数据问题
+func ScanOne(rows *sql.Rows, dest any) error {
+	return scan(rows, dest, newOptions(), true)
+}
+
+// scan 读取第一行记录，复制到 dest. 若 one 为 true, 则要求 rows 只有一行记录
+func scan(rows *sql.Rows, dest any, o *options, one bool) error {
 	defer rows.Close()
 
 	if !rows.Next() {
@@ -56,12 +73,41 @@ func Scan(rows *sql.Rows, dest any) error {
 		return err
 	}
 
-	// 映射查询字段和结构体字段
-	fields := mappingByColumns(columns, ev)
-	if err := rows.Scan(fields.values()...); err != nil {
+	var (
+		values []any
+		fields structFields
+	)
+	if cs, ok := columnScanner(rv, o); ok {
+		values = make([]any, len(columns))
+		cs.ScanBrows(columns, values)
+	} else {
+		// 映射查询字段和结构体字段
+		fields = mappingByColumns(columns, ev, o)
+		if o.checkColumnTypes {
+			if err := checkRowsColumnTypes(rows, fields, ev.Type()); err != nil {
+				return err
+			}
+		}
+		values = fields.values()
+	}
+
+	if err := rows.Scan(values...); err != nil {
+		return err
+	}
+	fields.assign()
+	if err := afterScan(rv); err != nil {
 		return err
 	}
 
+	if one {
+		if rows.Next() {
+			return ErrTooManyRows
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+
 	return rows.Close()
 }
 
@@ -81,6 +127,11 @@ func Scan(rows *sql.Rows, dest any) error {
 //	var users []User // or []*User
 //	ScanSlice(rows, &users)
 func ScanSlice(rows *sql.Rows, dest any) error {
+	return scanSlice(rows, dest, newOptions(), 0)
+}
+
+// scanSlice 同 ScanSlice, hint 为预分配的切片容量
+func scanSlice(rows *sql.Rows, dest any, o *options, hint int) error {
 	defer rows.Close()
 
 	rv := reflect.ValueOf(dest)
@@ -94,41 +145,198 @@ func ScanSlice(rows *sql.Rows, dest any) error {
 		return ErrScanSliceDestination
 	}
 
-	sliceElemType := slice.Type().Elem() // slice element
-	sliceElemInnerType := sliceElemType
-	switch sliceElemType.Kind() {
+	es, err := newElemScanner(rows, slice.Type().Elem(), o)
+	if err != nil {
+		return err
+	}
+
+	// 在副本上追加, 出错时 dest 保持不变
+	out := reflect.New(slice.Type()).Elem()
+	out.Set(slice)
+	if hint > 0 {
+		out.Grow(hint)
+	}
+
+	// arena 为 []*struct 批量分配的元素, 减少分配次数
+	var (
+		arena reflect.Value
+		used  int
+	)
+	for rows.Next() {
+		n := out.Len()
+		if n == out.Cap() {
+			out.Grow(1)
+		}
+		out.SetLen(n + 1)
+		elem := out.Index(n)
+
+		if !es.direct() {
+			one, err := es.scan()
+			if err != nil {
+				return err
+			}
+			elem.Set(one)
+			continue
+		}
+
+		if reflect.Pointer == es.elemType.Kind() {
+			if !arena.IsValid() || used == arena.Len() {
+				arena = reflect.MakeSlice(reflect.SliceOf(es.innerType), arenaSize(hint, n), arenaSize(hint, n))
+				used = 0
+			}
+			elem.Set(arena.Index(used).Addr())
+			used++
+			elem = elem.Elem()
+		} else {
+			// 复用 dest 的底层数组时可能有旧的值
+			elem.SetZero()
+		}
+		if err := es.scanInto(elem); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rv.Elem().Set(out)
+	return rows.Close()
+}
+
+// arenaSize 返回 []*struct 批量分配的元素个数, 有 hint 时按剩余行数分配, 否则随已读取的行数 n 增长, 最多 256.
+// 同一批的元素共享底层数组, 保留其中一个元素即保留整批, 因此有 hint 时也不超过 256
+func arenaSize(hint, n int) int {
+	if hint > n {
+		return min(hint-n, 256)
+	}
+
+	return min(max(n, 16), 256)
+}
+
+// elemScanner 读取 rows 的当前行到新的切片元素
+type elemScanner struct {
+	rows    *sql.Rows
+	o       *options
+	columns []string
+	// elemType 切片元素的类型, struct 或 *struct; innerType 为对应的 struct
+	elemType  reflect.Type
+	innerType reflect.Type
+	checked   bool
+
+	// plan 所有字段都不需要 nullzero 时, 每一列映射的字段, 只计算一次; 否则为 nil, 每一行重新映射
+	plan structFields
+	// generated 元素实现了 ColumnScanner, 通过 ScanBrows 获取每一列的目标
+	generated bool
+	// values 按 plan 或 ScanBrows 读取时复用的 rows.Scan 参数
+	values []any
+}
+
+func newElemScanner(rows *sql.Rows, elemType reflect.Type, o *options) (*elemScanner, error) {
+	innerType := elemType
+	switch elemType.Kind() {
 	case reflect.Pointer:
-		sliceElemInnerType = sliceElemInnerType.Elem()
-		if reflect.Struct != sliceElemInnerType.Kind() {
-			return ErrSliceElement
+		innerType = innerType.Elem()
+		if reflect.Struct != innerType.Kind() {
+			return nil, ErrSliceElement
 		}
 	case reflect.Struct:
 	default:
-		return ErrSliceElement
+		return nil, ErrSliceElement
 	}
 
 	columns, err := rows.Columns()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for rows.Next() {
-		one := reflect.New(sliceElemInnerType)
-		fields := mappingByColumns(columns, one)
-		if err := rows.Scan(fields.values()...); err != nil {
-			return err
+	es := &elemScanner{
+		rows:      rows,
+		o:         o,
+		columns:   columns,
+		elemType:  elemType,
+		innerType: innerType,
+		checked:   !o.checkColumnTypes,
+	}
+
+	tmp := reflect.New(innerType)
+	if _, ok := columnScanner(tmp, o); ok {
+		es.generated = true
+		es.values = make([]any, len(columns))
+	} else {
+		fields := mappingByColumns(columns, tmp, o)
+		if !es.checked {
+			if err := checkRowsColumnTypes(rows, fields, innerType); err != nil {
+				return nil, err
+			}
+			es.checked = true
 		}
-		if reflect.Pointer != sliceElemType.Kind() {
-			one = one.Elem()
+		if directFields(fields) {
+			es.plan = fields
+			es.values = make([]any, len(fields))
 		}
-		slice = reflect.Append(slice, one)
 	}
-	if err := rows.Err(); err != nil {
+
+	return es, nil
+}
+
+// directFields 判断 fields 是否都不需要 nullzero, 此时可以直接 Scan 到字段地址
+func directFields(fields structFields) bool {
+	for _, f := range fields {
+		if !f.ignore && f.nullZero {
+			return false
+		}
+	}
+
+	return true
+}
+
+// direct 判断是否可以通过 scanInto 直接读取到元素
+func (es *elemScanner) direct() bool {
+	return nil != es.plan || es.generated
+}
+
+// scan 读取当前行, 返回类型为 elemType 的元素
+func (es *elemScanner) scan() (reflect.Value, error) {
+	one := reflect.New(es.innerType)
+	if es.direct() {
+		if err := es.scanInto(one.Elem()); err != nil {
+			return reflect.Value{}, err
+		}
+	} else {
+		fields := mappingByColumns(es.columns, one, es.o)
+		if err := es.rows.Scan(fields.values()...); err != nil {
+			return reflect.Value{}, err
+		}
+		fields.assign()
+		if err := afterScan(one); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	if reflect.Pointer != es.elemType.Kind() {
+		one = one.Elem()
+	}
+
+	return one, nil
+}
+
+// scanInto 按 plan 或 ScanBrows 读取当前行到可寻址的结构体 rv
+func (es *elemScanner) scanInto(rv reflect.Value) error {
+	if es.generated {
+		rv.Addr().Interface().(ColumnScanner).ScanBrows(es.columns, es.values)
+	}
+	for i, f := range es.plan {
+		if f.ignore {
+			es.values[i] = _ignoreScan
+			continue
+		}
+		// 路径上的 nil 指针(如嵌入的 *struct)按需分配
+		es.values[i] = scanTarget(fieldByIndexAlloc(rv, f.index))
+	}
+	if err := es.rows.Scan(es.values...); err != nil {
 		return err
 	}
 
-	rv.Elem().Set(slice)
-	return rows.Close()
+	return afterScan(rv.Addr())
 }
 
 // _ignoreScan 忽略 scan
@@ -143,20 +351,43 @@ func (s *ignoreScan) Scan(v any) error {
 // tag 标签
 var _tagLabel = "db"
 
+// tag 选项: 列值为 NULL 时给字段赋零值, 如 `db:"note,nullzero"`
+const _tagOptionNullZero = "nullzero"
+
 type structFields []structField
 
 func (fs structFields) values() (out []any) {
-	for _, v := range fs {
-		if v.ignore {
+	for i, v := range fs {
+		switch {
+		case v.ignore:
 			out = append(out, _ignoreScan)
-		} else {
-			out = append(out, v.value.Addr().Interface())
+		case v.nullZero:
+			// 通过 *T 类型的中间对象接收 NULL
+			fs[i].holder = reflect.New(reflect.PointerTo(v.value.Type()))
+			out = append(out, scanTarget(fs[i].holder.Elem()))
+		default:
+			out = append(out, scanTarget(v.value))
 		}
 	}
 
 	return out
 }
 
+// assign 将中间对象接收的值复制到结构体字段, 需在 rows.Scan 之后调用
+func (fs structFields) assign() {
+	for _, v := range fs {
+		if !v.holder.IsValid() {
+			continue
+		}
+
+		if p := v.holder.Elem(); p.IsNil() {
+			v.value.SetZero()
+		} else {
+			v.value.Set(p.Elem())
+		}
+	}
+}
+
 type structField struct {
 	column string
 	// 是否忽略
@@ -165,17 +396,22 @@ type structField struct {
 	index []int
 	// field value
 	value reflect.Value
+	// 列值为 NULL 时，是否给字段赋零值
+	nullZero bool
+	// nullZero 字段接收列值的中间对象
+	holder reflect.Value
 }
 
-func mappingByColumns(columns []string, rv reflect.Value) structFields {
+// mappingByColumns 按 o 中的匹配方式，映射查询字段和结构体字段
+func mappingByColumns(columns []string, rv reflect.Value, o *options) structFields {
 	if reflect.Pointer == rv.Kind() {
 		rv = rv.Elem()
 	}
 
-	m := mapping(rv.Type(), _tagLabel)
+	match := o.match.matcher(typeMapping(rv.Type()))
 	out := make([]structField, 0, len(columns))
 	for _, v := range columns {
-		f, ok := m[v]
+		f, ok := match(v)
 		if !ok {
 			f.column = v
 			f.ignore = true
@@ -197,13 +433,33 @@ func mappingByColumns(columns []string, rv reflect.Value) structFields {
 			}
 		}
 
+		f.column = v
 		f.value = fv
+		f.nullZero = (f.nullZero || o.nullZero) && !nullSafe(fv.Type())
 		out = append(out, f)
 	}
 
 	return out
 }
 
+// _mappings 缓存结构体类型的映射关系: reflect.Type -> map[string]structField
+var _mappings sync.Map
+
+// typeMapping 返回 rt 按 _tagLabel 提取的映射关系, 结果会被缓存
+func typeMapping(rt reflect.Type) map[string]structField {
+	if reflect.Pointer == rt.Kind() {
+		rt = rt.Elem()
+	}
+
+	if m, ok := _mappings.Load(rt); ok {
+		return m.(map[string]structField)
+	}
+
+	m := mapping(rt, _tagLabel)
+	_mappings.Store(rt, m)
+	return m
+}
+
 // mapping 提取 reflect.Type 对象的 tag 和 structField 的映射关系.
 //
 // 提取规则
@@ -213,9 +469,9 @@ func mappingByColumns(columns []string, rv reflect.Value) structFields {
 //   - 不可导
 //
 // - structField 以下情况的，将遍历 field 对象的内部字段
-//   - 匿名内嵌对象
-//   - 指针对象
-//   - 非 time.Time 类型的结构体
+//   - 非叶子类型(见 leaf)的结构体或结构体指针, 包括匿名内嵌对象
+//
+// - 叶子类型(time.Time, 实现了 sql.Scanner 或 driver.Valuer 的类型, 通过 RegisterLeafType 注册的类型)的字段，按 tag 映射
 func mapping(rt reflect.Type, tag string) map[string]structField {
 	kind := rt.Kind()
 	if reflect.Pointer == kind {
@@ -233,24 +489,14 @@ func mapping(rt reflect.Type, tag string) map[string]structField {
 		case !field.IsExported():
 			// 不可导出
 			continue
-		case field.Anonymous:
-			// 内嵌
+		case nested(field.Type):
+			// 内嵌 或 结构体对象
 			mappingMerge(out, field.Index, mapping(field.Type, tag))
 			continue
-		case reflect.Struct == field.Type.Kind():
-			if "time.Time" != field.Type.String() {
-				mappingMerge(out, field.Index, mapping(field.Type, tag))
-				continue
-			}
-		case reflect.Pointer == field.Type.Kind():
-			if reflect.Struct == field.Type.Elem().Kind() && "time.Time" != field.Type.Elem().String() {
-				mappingMerge(out, field.Index, mapping(field.Type, tag))
-				continue
-			}
 		}
 
 		tagValue := field.Tag.Get(tag)
-		tagValue, _ = head(tagValue, ",")
+		tagValue, tagOptions := head(tagValue, ",")
 		if "-" == tagValue || "" == tagValue {
 			continue
 		}
@@ -258,14 +504,24 @@ func mapping(rt reflect.Type, tag string) map[string]structField {
 		mappingConflict(out, tagValue)
 
 		out[tagValue] = structField{
-			column: tagValue,
-			index:  []int{i},
+			column:   tagValue,
+			index:    []int{i},
+			nullZero: hasTagOption(tagOptions, _tagOptionNullZero),
 		}
 	}
 
 	return out
 }
 
+// nested 判断字段类型是否需要展开遍历其内部字段: 非叶子类型(见 leaf)的结构体或结构体指针
+func nested(rt reflect.Type) bool {
+	if reflect.Pointer == rt.Kind() {
+		rt = rt.Elem()
+	}
+
+	return reflect.Struct == rt.Kind() && !leaf(rt)
+}
+
 // mappingConflict tag 冲突检查
 func mappingConflict(m map[string]structField, tag string) {
 	if _, ok := m[tag]; ok {
@@ -282,6 +538,19 @@ func mappingMerge(dest map[string]structField, parentIndex []int, source map[str
 	}
 }
 
+// hasTagOption 判断 tag 中逗号分隔的选项列表 options 是否包含 option
+func hasTagOption(options, option string) bool {
+	for "" != options {
+		var name string
+		name, options = head(options, ",")
+		if option == strings.TrimSpace(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func head(str, sep string) (head string, tail string) {
 	idx := strings.Index(str, sep)
 	if idx < 0 {
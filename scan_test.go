@@ -4,10 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"runtime"
 	"sort"
 	"strconv"
 	"testing"
 	"time"
+
+	"github.com/beanscc/brows/browstest"
 )
 
 func TestMapping(t *testing.T) {
@@ -268,7 +271,7 @@ func TestMappingByColumns(t *testing.T) {
 
 	for _, tt := range test {
 		t.Run(tt.name, func(t *testing.T) {
-			got := mappingByColumns(tt.columns, reflect.ValueOf(tt.ptr))
+			got := mappingByColumns(tt.columns, reflect.ValueOf(tt.ptr), newOptions())
 			if err := fnCompare(got, tt.want); err != nil && !tt.wantErr {
 				t.Errorf("TestMappingByColumns failed. err:%v", err)
 			}
@@ -342,9 +345,183 @@ func Test_mappingByColumns(t *testing.T) {
 	// }
 	// t.Logf("after mapping dest:%#v", dest)
 
-	fs := mappingByColumns(columns, reflect.ValueOf(dest))
+	fs := mappingByColumns(columns, reflect.ValueOf(dest), newOptions())
 	for i, v := range fs {
 		t.Logf("mappingByColumns column:%16s, idx:%2d, field: %#v", columns[i], i, v)
 	}
 	t.Logf("after mapping dest:%#v", dest)
 }
+
+func TestStructFields_NullZero(t *testing.T) {
+	type T struct {
+		Name  string  `db:"name,nullzero"`
+		Age   int     `db:"age, nullzero"`
+		Note  string  `db:"note"`
+		Email *string `db:"email,nullzero"`
+	}
+
+	dest := T{Name: "name", Age: 10, Note: "note"}
+	fields := mappingByColumns([]string{"name", "age", "note", "email"}, reflect.ValueOf(&dest), newOptions())
+	if !fields[0].nullZero || !fields[1].nullZero || fields[2].nullZero {
+		t.Fatalf("mappingByColumns got unexpected nullZero:%#v", fields)
+	}
+	if fields[3].nullZero {
+		t.Error("mappingByColumns want nullZero disabled for nullable field")
+	}
+
+	// 模拟 rows.Scan: name 为 NULL, age 为 20
+	values := fields.values()
+	name, ok := values[0].(**string)
+	if !ok {
+		t.Fatalf("values want **string, got:%T", values[0])
+	}
+	*name = nil
+	age, ok := values[1].(**int)
+	if !ok {
+		t.Fatalf("values want **int, got:%T", values[1])
+	}
+	*age = addPtr(20)
+	fields.assign()
+
+	if dest.Name != "" || dest.Age != 20 || dest.Note != "note" {
+		t.Errorf("assign got unexpected dest:%#v", dest)
+	}
+
+	// WithNullZero 对所有字段生效
+	fields = mappingByColumns([]string{"note"}, reflect.ValueOf(&dest), newOptions(WithNullZero()))
+	if !fields[0].nullZero {
+		t.Error("mappingByColumns want nullZero enabled by WithNullZero")
+	}
+}
+
+func TestHasTagOption(t *testing.T) {
+	test := []struct {
+		options string
+		want    bool
+	}{
+		{options: "", want: false},
+		{options: "nullzero", want: true},
+		{options: "omitempty, nullzero", want: true},
+		{options: "nullzeros", want: false},
+	}
+
+	for _, tt := range test {
+		if got := hasTagOption(tt.options, _tagOptionNullZero); got != tt.want {
+			t.Errorf("hasTagOption(%q) got:%v, want:%v", tt.options, got, tt.want)
+		}
+	}
+}
+
+type benchUser struct {
+	ID      int64     `db:"id"`
+	Name    string    `db:"name"`
+	Age     int64     `db:"age"`
+	Email   *string   `db:"email"`
+	Score   float64   `db:"score"`
+	Created time.Time `db:"created_at"`
+}
+
+// benchmarkScanSlice 读取 100 行到 []T, 额外报告每行的内存分配次数 allocs/row
+func benchmarkScanSlice[T any](b *testing.B, hint int) {
+	db := browstest.NewDB()
+	defer db.Close()
+	bs := New(db)
+
+	const n = 100
+	result := browstest.NewResult("id", "name", "age", "email", "score", "created_at")
+	at := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		result.AddRow(int64(i), "name", int64(20), nil, 1.5, at)
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		db.Push(result)
+		var out []T
+		if err := bs.Query(`select * from user`).SizeHint(hint).Scan(&out); err != nil {
+			b.Fatal(err)
+		}
+		if len(out) != n {
+			b.Fatalf("got %d rows", len(out))
+		}
+	}
+	b.StopTimer()
+	runtime.ReadMemStats(&after)
+	b.ReportMetric(float64(after.Mallocs-before.Mallocs)/float64(b.N*n), "allocs/row")
+}
+
+func BenchmarkScanSlice_Struct(b *testing.B) {
+	benchmarkScanSlice[benchUser](b, 0)
+}
+
+func BenchmarkScanSlice_Pointer(b *testing.B) {
+	benchmarkScanSlice[*benchUser](b, 0)
+}
+
+func BenchmarkScanSlice_StructSizeHint(b *testing.B) {
+	benchmarkScanSlice[benchUser](b, 100)
+}
+
+func BenchmarkScanSlice_PointerSizeHint(b *testing.B) {
+	benchmarkScanSlice[*benchUser](b, 100)
+}
+
+func TestScanSlice_Reuse(t *testing.T) {
+	type Inner struct {
+		Email *string `db:"email"`
+	}
+	type User struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+		*Inner
+	}
+
+	db := browstest.NewDB(
+		browstest.NewResult("id", "email").AddRow(int64(1), "a@b.c").AddRow(int64(2), nil),
+		browstest.NewResult("id", "name").AddRow(int64(3), nil),
+	)
+	defer db.Close()
+	b := New(db)
+
+	// 底层数组中的旧值不能残留
+	backing := []User{{ID: 100, Name: "old"}, {ID: 101, Name: "old"}, {ID: 102, Name: "old"}}
+	users := backing[:1]
+	if err := b.Query(`select id,email from user`).SizeHint(2).Scan(&users); err != nil {
+		t.Fatalf("Scan err:%v", err)
+	}
+	if len(users) != 3 || users[0].ID != 100 || users[1].Name != "" || *users[1].Email != "a@b.c" || users[2].Inner == nil || users[2].Email != nil {
+		t.Errorf("Scan got:%+v", users)
+	}
+
+	// 出错时 dest 不变
+	before := users
+	if err := b.Query(`select id,name from user`).Scan(&users); err == nil {
+		t.Fatalf("Scan want NULL to string error")
+	}
+	if len(users) != len(before) || &users[0] != &before[0] {
+		t.Errorf("Scan error want dest unchanged, got:%+v", users)
+	}
+}
+
+func TestArenaSize(t *testing.T) {
+	tests := []struct {
+		hint, n, want int
+	}{
+		{0, 0, 16},
+		{0, 100, 100},
+		{0, 1000, 256},
+		{100, 0, 100},
+		{100, 60, 40},
+		{100, 100, 100},
+		{1000000, 0, 256},
+		{1000000, 999900, 100},
+	}
+	for _, tt := range tests {
+		if got := arenaSize(tt.hint, tt.n); got != tt.want {
+			t.Errorf("arenaSize(%d, %d) got:%v, want:%v", tt.hint, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package brows
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SlowLog 慢查询日志的配置
+type SlowLog struct {
+	// Logger 日志输出, 默认 slog.Default()
+	Logger *slog.Logger
+	// Level 日志级别, 为 nil 时默认 slog.LevelWarn. 可直接使用 slog.Level, 如 slog.LevelInfo
+	Level slog.Leveler
+	// Threshold 耗时(从执行查询到读取完所有行记录)超过 Threshold 的查询记为慢查询
+	Threshold time.Duration
+	// SampleRate 慢查询的采样率, 取值 (0, 1], 默认 1 即全部记录
+	SampleRate float64
+	// Redact 判断参数对应的列是否敏感, 敏感列的参数记录为 [REDACTED]. 默认 RedactSensitive
+	// 参数对应的列通过 SQL 推断, 如 password = ?, token IN (?,?), insert into t (a,b) values (?,?)
+	Redact func(column string) bool
+	// RedactUnknown 无法推断列的参数(如 insert into t values (?,?), 函数参数)是否记录为 [REDACTED].
+	// 默认 false 即原样记录, 此时无法推断列的敏感参数会明文输出到日志
+	RedactUnknown bool
+}
+
+// WithSlowLog 开启慢查询日志, 记录 SQL、耗时、读取的行数、调用位置和参数(敏感列的参数脱敏)
+//
+// example:
+//
+//	New(db, WithSlowLog(SlowLog{Threshold: 200 * time.Millisecond, SampleRate: 0.1}))
+func WithSlowLog(l SlowLog) Option {
+	return func(o *options) {
+		if nil == l.Logger {
+			l.Logger = slog.Default()
+		}
+		if nil == l.Level {
+			l.Level = slog.LevelWarn
+		}
+		if l.SampleRate <= 0 || l.SampleRate > 1 {
+			l.SampleRate = 1
+		}
+		if nil == l.Redact {
+			l.Redact = RedactSensitive
+		}
+		o.slowLog = &l
+	}
+}
+
+// _sensitiveColumns RedactSensitive 认为敏感的列名关键字
+var _sensitiveColumns = []string{"password", "passwd", "pwd", "secret", "token", "salt", "credential", "private_key", "api_key", "id_card", "ssn"}
+
+// RedactSensitive 列名(忽略大小写)包含 password, secret, token 等关键字时返回 true
+func RedactSensitive(column string) bool {
+	column = strings.ToLower(column)
+	for _, v := range _sensitiveColumns {
+		if strings.Contains(column, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RedactColumns 返回仅 columns 中的列(忽略大小写和表别名)为敏感列的 Redact
+func RedactColumns(columns ...string) func(column string) bool {
+	m := make(map[string]bool, len(columns))
+	for _, v := range columns {
+		m[strings.ToLower(v)] = true
+	}
+
+	return func(column string) bool {
+		return m[strings.ToLower(column)]
+	}
+}
+
+const _redacted = "[REDACTED]"
+
+// log 记录 rs 的慢查询, err 为查询或读取的错误
+func (l *SlowLog) log(ctx context.Context, rs *Rows, err error) {
+	d := time.Since(rs.start)
+	if d < l.Threshold || (l.SampleRate < 1 && rand.Float64() >= l.SampleRate) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("sql", rs.query),
+		slog.Duration("duration", d),
+		slog.Int("rows", rs.scanned),
+		slog.String("caller", rs.caller),
+		slog.Any("args", l.redact(rs.query, rs.args)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("err", err))
+	}
+
+	// ctx 可能已超时, 日志不应受其影响
+	l.Logger.LogAttrs(context.WithoutCancel(ctx), l.Level.Level(), "brows: slow query", attrs...)
+}
+
+// redact 返回脱敏后的参数
+func (l *SlowLog) redact(query string, args []any) []any {
+	if 0 == len(args) {
+		return args
+	}
+
+	columns := argColumns(query, len(args))
+	out := make([]any, len(args))
+	for i, v := range args {
+		if ("" == columns[i] && l.RedactUnknown) || ("" != columns[i] && l.Redact(columns[i])) {
+			out[i] = _redacted
+			continue
+		}
+		out[i] = v
+	}
+
+	return out
+}
+
+// _packageDir brows 包的目录, 用于跳过包内的调用
+var _packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+type callerKey struct{}
+
+// withCaller 返回记录了调用位置 at 的 ctx, 用于在其他 goroutine 中发起的查询(如 QueryCached 合并的查询)
+func withCaller(ctx context.Context, at string) context.Context {
+	return context.WithValue(ctx, callerKey{}, at)
+}
+
+// queryCaller 返回 ctx 中记录的调用位置, 没有时返回 caller()
+func queryCaller(ctx context.Context) string {
+	if at, ok := ctx.Value(callerKey{}).(string); ok {
+		return at
+	}
+
+	return caller()
+}
+
+// caller 返回 brows 包外(包括包内的测试)最近的调用位置 file:line
+func caller() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != _packageDir || strings.HasSuffix(frame.File, "_test.go") {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// argColumns 推断 query 中 n 个参数对应的列名(去掉表别名), 无法推断时为空字符串. 支持 ? 和 $n 占位符
+func argColumns(query string, n int) []string {
+	out := make([]string, n)
+	tokens := sqlTokens(query)
+
+	// parens 未闭合的 ( 的序号
+	var parens []int
+	next := 0
+	for i, t := range tokens {
+		switch {
+		case "(" == t:
+			parens = append(parens, i)
+			continue
+		case ")" == t:
+			if len(parens) > 0 {
+				parens = parens[:len(parens)-1]
+			}
+			continue
+		case "?" == t:
+			next++
+		case strings.HasPrefix(t, "$") && len(t) > 1:
+			v, err := strconv.Atoi(t[1:])
+			if err != nil {
+				continue
+			}
+			next = v
+		default:
+			continue
+		}
+		if next < 1 || next > n {
+			continue
+		}
+
+		column := ""
+		if i > 0 && isCompareOperator(tokens[i-1]) {
+			column = identAt(tokens, i-2)
+		} else if len(parens) > 0 {
+			open := parens[len(parens)-1]
+			switch {
+			case open > 0 && strings.EqualFold(tokens[open-1], "IN"):
+				column = identAt(tokens, open-2)
+				if strings.EqualFold(column, "NOT") {
+					column = identAt(tokens, open-3)
+				}
+			case open > 0 && strings.EqualFold(tokens[open-1], "VALUES"):
+				column = valuesColumn(tokens, open, i)
+			}
+		}
+		out[next-1] = column
+	}
+
+	return out
+}
+
+// valuesColumn 返回 insert into t (a,b) values (?,?) 中 values 列表第 open 个 ( 内 i 处参数对应的列
+func valuesColumn(tokens []string, open, i int) string {
+	pos := 0
+	for j := open + 1; j < i; j++ {
+		if "," == tokens[j] {
+			pos++
+		}
+	}
+
+	// values 前的列名列表
+	end := open - 2
+	if end < 0 || ")" != tokens[end] {
+		return ""
+	}
+	var columns []string
+	for j := end - 1; j >= 0 && "(" != tokens[j]; j-- {
+		if "," != tokens[j] {
+			columns = append([]string{tokens[j]}, columns...)
+		}
+	}
+	if pos >= len(columns) {
+		return ""
+	}
+
+	return stripQualifier(columns[pos])
+}
+
+func isCompareOperator(t string) bool {
+	switch strings.ToUpper(t) {
+	case "=", "<>", "!=", "<", ">", "<=", ">=", "<=>", "LIKE":
+		return true
+	default:
+		return false
+	}
+}
+
+// identAt 返回 tokens[i] 的标识符(去掉表别名), 不是标识符时返回空字符串
+func identAt(tokens []string, i int) string {
+	if i < 0 || i >= len(tokens) {
+		return ""
+	}
+	r := []rune(tokens[i])
+	if 0 == len(r) || !(unicode.IsLetter(r[0]) || '_' == r[0]) {
+		return ""
+	}
+
+	return stripQualifier(tokens[i])
+}
+
+func stripQualifier(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// sqlTokens 拆分 query 为标识符(含 . 限定, 去掉引号)、占位符、括号、逗号和运算符, 忽略字符串和数字
+func sqlTokens(query string) []string {
+	var out []string
+	rs := []rune(query)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case '\'' == r || '"' == r:
+			// 字符串
+			j := i + 1
+			for j < len(rs) && rs[j] != r {
+				if '\\' == rs[j] {
+					j++
+				}
+				j++
+			}
+			out = append(out, "''")
+			i = j + 1
+		case '`' == r || unicode.IsLetter(r) || '_' == r:
+			var b strings.Builder
+			j := i
+			for j < len(rs) {
+				if '`' == rs[j] {
+					k := j + 1
+					for k < len(rs) && '`' != rs[k] {
+						k++
+					}
+					b.WriteString(string(rs[j+1 : min(k, len(rs))]))
+					j = k + 1
+					continue
+				}
+				if '.' == rs[j] || '_' == rs[j] || '$' == rs[j] || unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j]) {
+					b.WriteRune(rs[j])
+					j++
+					continue
+				}
+				break
+			}
+			out = append(out, b.String())
+			i = j
+		case '$' == r:
+			j := i + 1
+			for j < len(rs) && unicode.IsDigit(rs[j]) {
+				j++
+			}
+			out = append(out, string(rs[i:j]))
+			i = j
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || '.' == rs[j]) {
+				j++
+			}
+			out = append(out, "0")
+			i = j
+		case strings.ContainsRune("<>!=", r):
+			j := i
+			for j < len(rs) && strings.ContainsRune("<>!=", rs[j]) {
+				j++
+			}
+			out = append(out, string(rs[i:j]))
+			i = j
+		default:
+			out = append(out, string(r))
+			i++
+		}
+	}
+
+	return out
+}
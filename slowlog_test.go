@@ -0,0 +1,144 @@
+package brows
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beanscc/brows/browstest"
+)
+
+func TestArgColumns(t *testing.T) {
+	tests := []struct {
+		query string
+		n     int
+		want  []string
+	}{
+		{`select id from user where name = ? and u.password=? and age > 1`, 2, []string{"name", "password"}},
+		{"select id from user where `token` in (?, ?) and id not in (?) and x like ?", 4, []string{"token", "token", "id", "x"}},
+		{`insert into user (name, ` + "`salt`" + `, age) values (?, ?, ?)`, 3, []string{"name", "salt", "age"}},
+		{`update user set secret = $2 where id = $1`, 2, []string{"id", "secret"}},
+		{`select id from user where name = '?' and upper(email) = ? limit ?`, 2, []string{"", ""}},
+		{`select ? + 1`, 1, []string{""}},
+	}
+	for _, tt := range tests {
+		got := argColumns(tt.query, tt.n)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("argColumns(%s) got:%q, want:%q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	if !RedactSensitive("User_Password") || !RedactSensitive("access_token") || RedactSensitive("name") {
+		t.Errorf("RedactSensitive got unexpected result")
+	}
+	redact := RedactColumns("Email")
+	if !redact("email") || redact("password") {
+		t.Errorf("RedactColumns got unexpected result")
+	}
+}
+
+func TestBrows_SlowLog(t *testing.T) {
+	users := func() *browstest.Result {
+		return browstest.NewResult("id").AddRow(int64(1)).AddRow(int64(2))
+	}
+	db := browstest.NewDB(users(), users(), users())
+	defer db.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	b := New(db, WithSlowLog(SlowLog{Logger: logger}))
+
+	var out []struct {
+		ID int64 `db:"id"`
+	}
+	rs := b.Query(`select id from user where name = ? and password = ?`, "a", "secret")
+	if err := rs.Scan(&out); err != nil {
+		t.Fatalf("Scan err:%v", err)
+	}
+	rs.Close()
+
+	var record struct {
+		Level    string
+		Msg      string
+		SQL      string
+		Rows     int
+		Caller   string
+		Args     []any
+		Duration int64
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("SlowLog got %d lines:%s", len(lines), buf.String())
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Unmarshal err:%v", err)
+	}
+	if record.Level != "WARN" || record.SQL != `select id from user where name = ? and password = ?` || record.Rows != 2 ||
+		!strings.Contains(record.Caller, "slowlog_test.go:") || len(record.Args) != 2 || record.Args[0] != "a" || record.Args[1] != _redacted {
+		t.Errorf("SlowLog got:%s", lines[0])
+	}
+
+	// 未超过阈值或未采样时不记录
+	buf.Reset()
+	b = New(db, WithSlowLog(SlowLog{Logger: logger, Threshold: 1 << 40}))
+	if err := b.Query(`select id from user`).Scan(&out); err != nil || buf.Len() > 0 {
+		t.Errorf("SlowLog under threshold got:%s, err:%v", buf.String(), err)
+	}
+	b = New(db, WithSlowLog(SlowLog{Logger: logger, SampleRate: 1e-12}))
+	if err := b.Query(`select id from user`).Scan(&out); err != nil || buf.Len() > 0 {
+		t.Errorf("SlowLog not sampled got:%s, err:%v", buf.String(), err)
+	}
+
+	// slog.LevelInfo 为零值, 也可指定; 无法推断列的参数可脱敏
+	db.Push(users())
+	b = New(db, WithSlowLog(SlowLog{Logger: logger, Level: slog.LevelInfo, RedactUnknown: true}))
+	if err := b.Query(`select id from user where name = ? and md5(?) = sign`, "a", "secret").Scan(&out); err != nil {
+		t.Fatalf("Scan err:%v", err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil || record.Level != "INFO" ||
+		len(record.Args) != 2 || record.Args[0] != "a" || record.Args[1] != _redacted {
+		t.Errorf("SlowLog got:%s, err:%v", buf.String(), err)
+	}
+}
+
+func TestBrows_SlowLog_Caller(t *testing.T) {
+	users := func() *browstest.Result {
+		return browstest.NewResult("id").AddRow(int64(1)).AddRow(int64(2))
+	}
+	db := browstest.NewDB(users(), users())
+	defer db.Close()
+
+	var buf bytes.Buffer
+	b := New(db, WithSlowLog(SlowLog{Logger: slog.New(slog.NewJSONHandler(&buf, nil))}), WithCache(NewLRUCache(16)))
+
+	type ID struct {
+		ID int64 `db:"id"`
+	}
+	// 查询在 flightGroup 的 goroutine 中执行
+	var cached []ID
+	if err := b.QueryCached(context.Background(), time.Minute, `select id from user`).Scan(&cached); err != nil {
+		t.Fatalf("QueryCached err:%v", err)
+	}
+	// 读取在 ScanBatches 的 goroutine 中完成
+	err := ScanBatches(context.Background(), b.Query(`select id from user`), 1, func(batch []ID) error { return nil })
+	if err != nil {
+		t.Fatalf("ScanBatches err:%v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("SlowLog got %d lines:%s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		var record struct{ Caller string }
+		if err := json.Unmarshal([]byte(line), &record); err != nil || !strings.Contains(record.Caller, "slowlog_test.go:") {
+			t.Errorf("SlowLog caller got:%s, err:%v", line, err)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package brows
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// Preparer 可以预编译语句的 Query, 如 *sql.DB, *sql.Conn, *sql.Tx
+type Preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// WithStmtCache 开启预编译语句缓存, 最多缓存 size 条(按 LRU 淘汰并关闭), Query/QueryRow 等将使用缓存的 *sql.Stmt 查询.
+// 仅当 New 的 Query 实现了 Preparer 且不是 *sql.Tx 时生效; 事务中请使用 Brows.WithTx 复用缓存.
+// 不再使用时需调用 Brows.Close 关闭缓存的语句
+//
+// example:
+//
+//	b := New(db, WithStmtCache(256))
+//	defer b.Close()
+func WithStmtCache(size int) Option {
+	return func(o *options) {
+		o.stmtCacheSize = size
+	}
+}
+
+// StmtCacheStats 预编译语句缓存的统计
+type StmtCacheStats struct {
+	// Size 当前缓存的语句数
+	Size int
+	// Hits 命中次数
+	Hits uint64
+	// Misses 未命中(即预编译)次数
+	Misses uint64
+	// Evictions 淘汰次数
+	Evictions uint64
+}
+
+// HitRate 返回命中率, 没有查询时为 0
+func (s StmtCacheStats) HitRate() float64 {
+	if 0 == s.Hits+s.Misses {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// WithTx 返回在事务 tx 中查询的 Brows, 配置与 b 相同. b 开启了语句缓存时, 缓存的语句通过 tx.StmtContext 绑定到 tx 后使用,
+// 绑定的语句随 tx 提交或回滚关闭
+func (b *Brows) WithTx(tx *sql.Tx) *Brows {
+	return b.with(tx)
+}
+
+// StmtCacheStats 返回预编译语句缓存的统计, 未开启缓存时返回零值
+func (b *Brows) StmtCacheStats() StmtCacheStats {
+	if nil == b.stmts {
+		return StmtCacheStats{}
+	}
+
+	return b.stmts.stats()
+}
+
+// Close 关闭缓存的预编译语句, 不会关闭 Query. 通过 WithTx 得到的 Brows 与 b 共用缓存
+func (b *Brows) Close() error {
+	if nil == b.stmts {
+		return nil
+	}
+
+	return b.stmts.close()
+}
+
+// queryStmt 使用缓存的语句查询
+func (b *Brows) queryStmt(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	cs, err := b.stmts.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	// 语句关闭时会等待未关闭的 rows, 因此查询返回后即可释放
+	defer b.stmts.release(cs)
+
+	stmt := cs.stmt
+	if nil != b.tx {
+		stmt = b.tx.StmtContext(ctx, stmt)
+	}
+
+	return stmt.QueryContext(ctx, args...)
+}
+
+var errStmtCacheClosed = errors.New("brows: statement cache closed")
+
+// stmtCache 预编译语句的 LRU 缓存
+type stmtCache struct {
+	preparer Preparer
+	size     int
+
+	mu        sync.Mutex
+	closed    bool
+	ll        *list.List
+	items     map[string]*list.Element
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+type cachedStmt struct {
+	query string
+	stmt  *sql.Stmt
+	// refs 正在使用的次数, 淘汰后为 0 时关闭
+	refs    int
+	evicted bool
+}
+
+func newStmtCache(preparer Preparer, size int) *stmtCache {
+	return &stmtCache{
+		preparer: preparer,
+		size:     size,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element, size),
+	}
+}
+
+// acquire 返回 query 的语句, 不存在时预编译并缓存. 使用完后需调用 release
+func (c *stmtCache) acquire(ctx context.Context, query string) (*cachedStmt, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, errStmtCacheClosed
+	}
+	if e, ok := c.items[query]; ok {
+		c.hits++
+		c.ll.MoveToFront(e)
+		cs := e.Value.(*cachedStmt)
+		cs.refs++
+		c.mu.Unlock()
+		return cs, nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	stmt, err := c.preparer.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		stmt.Close()
+		return nil, errStmtCacheClosed
+	}
+	// 并发预编译了同一条语句
+	if e, ok := c.items[query]; ok {
+		stmt.Close()
+		c.ll.MoveToFront(e)
+		cs := e.Value.(*cachedStmt)
+		cs.refs++
+		return cs, nil
+	}
+
+	cs := &cachedStmt{query: query, stmt: stmt, refs: 1}
+	c.items[query] = c.ll.PushFront(cs)
+	for c.ll.Len() > c.size {
+		c.evict(c.ll.Back())
+	}
+
+	return cs, nil
+}
+
+func (c *stmtCache) release(cs *cachedStmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cs.refs--
+	if cs.evicted && 0 == cs.refs {
+		cs.stmt.Close()
+	}
+}
+
+// evict 淘汰 e, 需持有锁
+func (c *stmtCache) evict(e *list.Element) {
+	cs := e.Value.(*cachedStmt)
+	c.ll.Remove(e)
+	delete(c.items, cs.query)
+	c.evictions++
+	cs.evicted = true
+	if 0 == cs.refs {
+		cs.stmt.Close()
+	}
+}
+
+func (c *stmtCache) stats() StmtCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return StmtCacheStats{
+		Size:      c.ll.Len(),
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
+
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	var errs []error
+	for e := c.ll.Front(); e != nil; e = e.Next() {
+		cs := e.Value.(*cachedStmt)
+		cs.evicted = true
+		if 0 == cs.refs {
+			errs = append(errs, cs.stmt.Close())
+		}
+	}
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,101 @@
+package brows
+
+import (
+	"context"
+	"testing"
+
+	"github.com/beanscc/brows/browstest"
+)
+
+func countCalls(db *browstest.DB, method browstest.Method) (n int) {
+	for _, v := range db.Calls() {
+		if v.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+func TestBrows_StmtCache(t *testing.T) {
+	id := func() *browstest.Result {
+		return browstest.NewResult("id").AddRow(int64(1))
+	}
+	db := browstest.NewDB(id(), id(), id(), id(), id())
+	defer db.Close()
+	b := New(db, WithStmtCache(1))
+
+	var out struct {
+		ID int64 `db:"id"`
+	}
+	for _, query := range []string{`select id from a`, `select id from a`, `select id from b`, `select id from a`} {
+		if err := b.QueryRow(query).Scan(&out); err != nil || out.ID != 1 {
+			t.Fatalf("QueryRow(%s) got:%v, err:%v", query, out, err)
+		}
+	}
+
+	want := StmtCacheStats{Size: 1, Hits: 1, Misses: 3, Evictions: 2}
+	if got := b.StmtCacheStats(); got != want {
+		t.Errorf("StmtCacheStats got:%+v, want:%+v", got, want)
+	}
+	if got := b.StmtCacheStats().HitRate(); got != 0.25 {
+		t.Errorf("HitRate got:%v", got)
+	}
+	if got := countCalls(db, browstest.MethodPrepare); got != 3 {
+		t.Errorf("prepare got:%v, want:3", got)
+	}
+
+	if err := b.Close(); err != nil {
+		t.Errorf("Close err:%v", err)
+	}
+	if err := b.QueryRow(`select id from a`).Scan(&out); err != errStmtCacheClosed {
+		t.Errorf("QueryRow after Close want errStmtCacheClosed, got:%v", err)
+	}
+}
+
+func TestBrows_StmtCache_Tx(t *testing.T) {
+	id := func() *browstest.Result {
+		return browstest.NewResult("id").AddRow(int64(1))
+	}
+	db := browstest.NewDB(id(), id(), id())
+	defer db.Close()
+	b := New(db, WithStmtCache(8))
+	defer b.Close()
+
+	var out []struct {
+		ID int64 `db:"id"`
+	}
+	if err := b.Query(`select id from a`).Scan(&out); err != nil {
+		t.Fatalf("Query err:%v", err)
+	}
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("BeginTx err:%v", err)
+	}
+	tb := b.WithTx(tx)
+	for i := 0; i < 2; i++ {
+		if err := tb.Query(`select id from a`).Scan(&out); err != nil {
+			t.Fatalf("tx Query err:%v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit err:%v", err)
+	}
+
+	if got := b.StmtCacheStats(); got.Hits != 2 || got.Misses != 1 {
+		t.Errorf("StmtCacheStats got:%+v", got)
+	}
+	if got := countCalls(db, browstest.MethodQuery); got != 3 {
+		t.Errorf("query got:%v, want:3", got)
+	}
+
+	// 直接使用 *sql.Tx 时不缓存
+	tx, err = db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("BeginTx err:%v", err)
+	}
+	defer tx.Rollback()
+	if New(tx, WithStmtCache(8)).stmts != nil {
+		t.Errorf("New(tx) want no stmt cache")
+	}
+}
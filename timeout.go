@@ -0,0 +1,102 @@
+package brows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutError 查询因 ctx 超时失败的错误, errors.Is(err, context.DeadlineExceeded) 为 true
+type TimeoutError struct {
+	// Query 超时的 SQL
+	Query string
+	// Timeout Brows 设置的超时时间, 超时来自调用方 ctx 的截止时间时为 0
+	Timeout time.Duration
+	// Err 查询或读取时返回的原始错误
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Timeout > 0 {
+		return fmt.Sprintf("brows: query timeout after %s: %s: %v", e.Timeout, e.Query, e.Err)
+	}
+
+	return fmt.Sprintf("brows: query deadline exceeded: %s: %v", e.Query, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() []error {
+	return []error{context.DeadlineExceeded, e.Err}
+}
+
+// WithDefaultTimeout 设置查询的默认超时时间, 仅在调用方 ctx 没有截止时间时生效, 可通过 WithQueryTimeout 为单次查询覆盖.
+// 超时时间包括执行查询和读取(Scan 等)所有行记录
+//
+// example:
+//
+//	New(db, WithDefaultTimeout(3*time.Second))
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+type timeoutKey struct{}
+
+// WithQueryTimeout 返回覆盖 WithDefaultTimeout 的 ctx, d 为 0 时表示不设置超时. 与 WithDefaultTimeout 相同, ctx 已有截止时间时不生效
+//
+// example:
+//
+//	err := b.QueryContext(WithQueryTimeout(ctx, time.Minute), `select * from report`).Scan(&rows)
+func WithQueryTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, d)
+}
+
+// withTimeout 返回查询使用的 ctx 和超时时间, 没有设置超时时 cancel 为 nil
+func (b *Brows) withTimeout(ctx context.Context) (context.Context, context.CancelFunc, time.Duration) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, nil, 0
+	}
+
+	d := b.opts.timeout
+	if v, ok := ctx.Value(timeoutKey{}).(time.Duration); ok {
+		d = v
+	}
+	if d <= 0 {
+		return ctx, nil, 0
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d)
+	return ctx, cancel, d
+}
+
+// finish 结束 rs 的查询: ctx 超时时将 err 包装为 TimeoutError, 记录慢查询并释放超时的 ctx. 可重复调用, 仅第一次记录慢查询
+func (rs *Rows) finish(err error) error {
+	if nil != rs.cancel {
+		defer rs.cancel()
+	}
+	if nil != err && nil != rs.ctx && errors.Is(rs.ctx.Err(), context.DeadlineExceeded) {
+		var te *TimeoutError
+		if !errors.As(err, &te) {
+			err = &TimeoutError{Query: rs.query, Timeout: rs.timeout, Err: err}
+		}
+	}
+
+	if !rs.finished {
+		rs.finished = true
+		if nil != rs.opts && nil != rs.opts.slowLog {
+			rs.opts.slowLog.log(rs.ctx, rs, err)
+		}
+	}
+
+	return err
+}
+
+// finishOne 同 finish, 用于 Row 读取一行记录
+func (rs *Rows) finishOne(err error) error {
+	if nil == err {
+		rs.scanned++
+	}
+
+	return rs.finish(err)
+}
@@ -0,0 +1,72 @@
+package brows
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beanscc/brows/browstest"
+)
+
+func TestBrows_DefaultTimeout(t *testing.T) {
+	slow := func() *browstest.Result {
+		r := browstest.NewResult("id").AddRow(int64(1))
+		r.Delay = 50 * time.Millisecond
+		return r
+	}
+	db := browstest.NewDB(slow(), slow(), slow())
+	defer db.Close()
+	b := New(db, WithDefaultTimeout(5*time.Millisecond))
+
+	var out struct {
+		ID int64 `db:"id"`
+	}
+	err := b.QueryRow(`select id from user`).Scan(&out)
+	var te *TimeoutError
+	if !errors.As(err, &te) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("QueryRow want TimeoutError, got:%v", err)
+	}
+	if te.Query != `select id from user` || te.Timeout != 5*time.Millisecond || !strings.Contains(err.Error(), te.Query) {
+		t.Errorf("TimeoutError got:%+v, %v", te, err)
+	}
+
+	// 单次覆盖
+	if err := b.QueryRowContext(WithQueryTimeout(context.Background(), 0), `select id from user`).Scan(&out); err != nil || out.ID != 1 {
+		t.Errorf("QueryRow with WithQueryTimeout(0) got:%v, err:%v", out, err)
+	}
+
+	// 调用方的截止时间优先
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := b.QueryRowContext(ctx, `select id from user`).Scan(&out); err != nil || out.ID != 1 {
+		t.Errorf("QueryRow with ctx deadline got:%v, err:%v", out, err)
+	}
+}
+
+func TestBrows_DefaultTimeout_Cancel(t *testing.T) {
+	db := browstest.NewDB(browstest.NewResult("id").AddRow(int64(1)), browstest.NewResult("id").AddRow(int64(1)))
+	defer db.Close()
+	b := New(db, WithDefaultTimeout(time.Minute))
+
+	rs := b.Query(`select id from user`)
+	if rs.cancel == nil || rs.ctx.Err() != nil {
+		t.Fatalf("Query want timeout ctx")
+	}
+	var out []struct {
+		ID int64 `db:"id"`
+	}
+	if err := rs.Scan(&out); err != nil {
+		t.Fatalf("Scan err:%v", err)
+	}
+	// 读取完成后释放 ctx, 且不会包装为超时错误
+	if !errors.Is(rs.ctx.Err(), context.Canceled) {
+		t.Errorf("ctx after Scan got:%v, want context.Canceled", rs.ctx.Err())
+	}
+
+	rs = b.Query(`select id from user`)
+	if err := rs.Close(); err != nil || !errors.Is(rs.ctx.Err(), context.Canceled) {
+		t.Errorf("Close got err:%v, ctx:%v", err, rs.ctx.Err())
+	}
+}
@@ -0,0 +1,143 @@
+package brows
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	ErrTagConflict      = errors.New("brows: tag conflict")
+	ErrUnsupportedField = errors.New("brows: unsupported field type")
+	ErrUnreachableField = errors.New("brows: unreachable field")
+)
+
+var _scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// Validate 检查 rt 是否可以作为 Scan/ScanSlice 的目标结构体, rt 必须是 struct 或 *struct.
+//
+// 检查以下问题，并返回所有问题合并后的错误(errors.Join):
+//   - tag 重复 (ErrTagConflict), 此时 Scan 会 panic
+//   - 字段类型不支持 Scan (ErrUnsupportedField), 如 chan, func, map, complex 以及非 []byte 的切片等，
+//     实现了 sql.Scanner 的类型和通过 RegisterLeafType 注册(提供了转换函数)的类型除外
+//   - 字段不可达 (ErrUnreachableField), 如递归引用自身的结构体、多级指针的结构体
+//
+// 建议在单元测试或 init 中调用，尽早发现模型定义问题
+func Validate(rt reflect.Type) error {
+	if reflect.Pointer == rt.Kind() {
+		rt = rt.Elem()
+	}
+
+	if reflect.Struct != rt.Kind() {
+		return fmt.Errorf("%w: got %s", ErrScanDestination, rt)
+	}
+
+	v := &validator{tags: make(map[string]string)}
+	v.walk(rt, rt.Name(), map[reflect.Type]bool{rt: true})
+	return errors.Join(v.errs...)
+}
+
+// MustRegister 检查并注册结构体 T, 若 Validate 检查失败则 panic. 注册后 T 的映射关系会被缓存
+//
+// example:
+//
+//	func init() {
+//		brows.MustRegister[User]()
+//	}
+func MustRegister[T any]() {
+	rt := reflect.TypeOf((*T)(nil)).Elem()
+	if err := Validate(rt); err != nil {
+		panic(err)
+	}
+
+	typeMapping(rt)
+}
+
+type validator struct {
+	// tag -> 字段路径
+	tags map[string]string
+	errs []error
+}
+
+// walk 按 mapping 的规则遍历结构体字段. visiting 记录当前路径上的结构体类型，用于发现递归引用
+func (v *validator) walk(rt reflect.Type, path string, visiting map[reflect.Type]bool) {
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldPath := path + "." + field.Name
+		if nested(field.Type) {
+			inner := field.Type
+			if reflect.Pointer == inner.Kind() {
+				inner = inner.Elem()
+			}
+
+			if visiting[inner] {
+				v.errorf(ErrUnreachableField, "%s: recursive reference to %s", fieldPath, inner)
+				continue
+			}
+
+			visiting[inner] = true
+			v.walk(inner, fieldPath, visiting)
+			delete(visiting, inner)
+			continue
+		}
+
+		if reflect.Pointer == field.Type.Kind() && reflect.Pointer == field.Type.Elem().Kind() {
+			elem := field.Type
+			for reflect.Pointer == elem.Kind() {
+				elem = elem.Elem()
+			}
+			if nested(elem) {
+				v.errorf(ErrUnreachableField, "%s: fields of multi-level pointer %s are not mapped", fieldPath, field.Type)
+				continue
+			}
+		}
+
+		tagValue, _ := head(field.Tag.Get(_tagLabel), ",")
+		if "-" == tagValue || "" == tagValue {
+			continue
+		}
+
+		if exist, ok := v.tags[tagValue]; ok {
+			v.errorf(ErrTagConflict, "%s: tag %q already used by %s", fieldPath, tagValue, exist)
+			continue
+		}
+		v.tags[tagValue] = fieldPath
+
+		if !scannable(field.Type) {
+			v.errorf(ErrUnsupportedField, "%s: %s", fieldPath, field.Type)
+		}
+	}
+}
+
+func (v *validator) errorf(err error, format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf("%w: "+format, append([]any{err}, args...)...))
+}
+
+// scannable 判断 rt 类型的字段是否可作为 database/sql Rows.Scan 的目标
+func scannable(rt reflect.Type) bool {
+	if rt.Implements(_scannerType) || reflect.PointerTo(rt).Implements(_scannerType) {
+		return true
+	}
+
+	switch rt.Kind() {
+	case reflect.Pointer:
+		return scannable(rt.Elem())
+	case reflect.Bool, reflect.String, reflect.Interface,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		// []byte, sql.RawBytes 等
+		return reflect.Uint8 == rt.Elem().Kind()
+	case reflect.Struct:
+		return "time.Time" == rt.String() || registeredLeaf(rt)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,113 @@
+package brows
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	type Inner struct {
+		F1 string `db:"f1"`
+	}
+
+	type Valid struct {
+		ID        int64          `db:"id"`
+		Name      *string        `db:"name"`
+		Raw       []byte         `db:"raw"`
+		Note      sql.NullString `db:"note"`
+		CreatedAt time.Time      `db:"created_at"`
+		Any       any            `db:"any"`
+		Ignore    chan int       `db:"-"`
+		NoTag     map[string]int
+
+		Inner
+	}
+
+	type Node struct {
+		ID   int   `db:"id"`
+		Next *Node `db:"next"`
+	}
+
+	type Status int
+
+	type Invalid struct {
+		ID     int            `db:"id"`
+		Dup    string         `db:"id"`
+		Ch     chan int       `db:"ch"`
+		M      map[string]int `db:"m"`
+		Ints   []int          `db:"ints"`
+		Inner  **Inner
+		Status `db:"status"`
+	}
+
+	test := []struct {
+		name  string
+		rt    reflect.Type
+		wants []error
+	}{
+		{name: "valid", rt: reflect.TypeOf(Valid{})},
+		{name: "valid pointer", rt: reflect.TypeOf(&Valid{})},
+		{name: "not struct", rt: reflect.TypeOf(1), wants: []error{ErrScanDestination}},
+		{name: "recursive", rt: reflect.TypeOf(Node{}), wants: []error{ErrUnreachableField}},
+		{
+			name: "invalid",
+			rt:   reflect.TypeOf(Invalid{}),
+			wants: []error{
+				ErrTagConflict,
+				ErrUnsupportedField, ErrUnsupportedField, ErrUnsupportedField,
+				ErrUnreachableField,
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.rt)
+			if len(tt.wants) == 0 {
+				if err != nil {
+					t.Errorf("Validate err:%v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("Validate want err")
+			}
+			for _, want := range tt.wants {
+				if !errors.Is(err, want) {
+					t.Errorf("Validate want err:%v, got:%v", want, err)
+				}
+			}
+			if lines := strings.Count(err.Error(), "\n") + 1; lines != len(tt.wants) {
+				t.Errorf("Validate want %d errors, got:%v", len(tt.wants), err)
+			}
+		})
+	}
+}
+
+func TestMustRegister(t *testing.T) {
+	type Valid struct {
+		ID int `db:"id"`
+	}
+
+	type Invalid struct {
+		ID  int `db:"id"`
+		Dup int `db:"id"`
+	}
+
+	MustRegister[Valid]()
+	if _, ok := _mappings.Load(reflect.TypeOf(Valid{})); !ok {
+		t.Error("MustRegister want mapping cached")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustRegister want panic")
+		}
+	}()
+	MustRegister[Invalid]()
+}